@@ -0,0 +1,191 @@
+package bbcode
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Steam limits in bytes of utf-8 text.
+const (
+	MaxTitleLength       = 128
+	MaxDescriptionLength = 8000
+	MaxChangeNoteLength  = 8000
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a bbcode text.
+// Line and column start at 1, both are 0 for issues concerning the whole text.
+type Issue struct {
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func (issue Issue) String() string {
+	if issue.Line == 0 {
+		return fmt.Sprintf("%s: %s", issue.Severity, issue.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", issue.Line, issue.Column, issue.Severity, issue.Message)
+}
+
+// knownTags are the tags supported by steam, tags that do not need to be closed are mapped to false.
+var knownTags = map[string]bool{
+	"h1": true, "h2": true, "h3": true,
+	"b": true, "i": true, "u": true, "strike": true, "spoiler": true,
+	"noparse": true, "code": true, "quote": true,
+	"url": true, "img": true, "previewyoutube": true,
+	"list": true, "olist": true, "*": false,
+	"table": true, "tr": true, "th": true, "td": true,
+	"hr": true,
+}
+
+// rawTags do not interpret tags in their content.
+var rawTags = map[string]bool{"noparse": true, "code": true}
+
+type openTag struct {
+	name   string
+	line   int
+	column int
+}
+
+// Lint checks that all tags are known and balanced, links are valid
+// and the text does not exceed the maximum length.
+func Lint(text string, maxLength int) []Issue {
+	var issues []Issue
+	if len(text) > maxLength {
+		issues = append(issues, Issue{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("text is %d bytes long, steam allows at most %d", len(text), maxLength),
+		})
+	}
+
+	var stack []openTag
+	line, column := 1, 1
+	for offset := 0; offset < len(text); {
+		character, size := utf8.DecodeRuneInString(text[offset:])
+		if character != '[' {
+			offset += size
+			if character == '\n' {
+				line, column = line+1, 1
+			} else {
+				column++
+			}
+			continue
+		}
+
+		end := strings.IndexAny(text[offset+1:], "[]\n")
+		if end < 0 || text[offset+1+end] != ']' {
+			offset++
+			column++
+			continue
+		}
+		tag := text[offset+1 : offset+1+end]
+		closing := strings.HasPrefix(tag, "/")
+		name, argument, _ := strings.Cut(strings.TrimPrefix(tag, "/"), "=")
+		name, _, _ = strings.Cut(name, " ")
+		name = strings.ToLower(name)
+
+		needsClosing, known := knownTags[name]
+		switch {
+		case !isTagName(name):
+			// Regular text in brackets, e.g. [1.0.1]
+		case !known:
+			issues = append(issues, Issue{line, column, SeverityWarning, fmt.Sprintf("unknown tag [%s] is shown as text", tag)})
+		case closing:
+			issues = closeTag(issues, &stack, name, line, column)
+		default:
+			if name == "url" && strings.Contains(tag, "=") {
+				if issue := lintURL(argument); issue != "" {
+					issues = append(issues, Issue{line, column, SeverityError, issue})
+				}
+			}
+			if needsClosing {
+				stack = append(stack, openTag{name, line, column})
+			}
+			if rawTags[name] {
+				// Skip to the end of the raw tag
+				closingTag := "[/" + name + "]"
+				rawEnd := strings.Index(strings.ToLower(text[offset+end+2:]), closingTag)
+				if rawEnd >= 0 {
+					skipped := text[offset : offset+end+2+rawEnd]
+					line, column = advance(skipped, line, column)
+					offset += len(skipped)
+					continue
+				}
+			}
+		}
+
+		line, column = advance(text[offset:offset+end+2], line, column)
+		offset += end + 2
+	}
+
+	for index := len(stack) - 1; index >= 0; index-- {
+		tag := stack[index]
+		issues = append(issues, Issue{tag.line, tag.column, SeverityError, fmt.Sprintf("tag [%s] is never closed", tag.name)})
+	}
+	return issues
+}
+
+func closeTag(issues []Issue, stack *[]openTag, name string, line int, column int) []Issue {
+	for index := len(*stack) - 1; index >= 0; index-- {
+		if (*stack)[index].name != name {
+			continue
+		}
+		for _, unclosed := range (*stack)[index+1:] {
+			issues = append(issues, Issue{unclosed.line, unclosed.column, SeverityError, fmt.Sprintf("tag [%s] is not closed before [/%s]", unclosed.name, name)})
+		}
+		*stack = (*stack)[:index]
+		return issues
+	}
+	return append(issues, Issue{line, column, SeverityError, fmt.Sprintf("closing tag [/%s] without opening tag", name)})
+}
+
+func lintURL(argument string) string {
+	argument = strings.Trim(argument, `"'`)
+	if argument == "" {
+		return "link [url=] has no target"
+	}
+	target, err := url.Parse(argument)
+	if err != nil {
+		return fmt.Sprintf("malformed link target '%s': %v", argument, err)
+	}
+	if target.Scheme == "" || (target.Host == "" && target.Opaque == "" && target.Path == "") {
+		return fmt.Sprintf("malformed link target '%s': links need an absolute url like https://example.com", argument)
+	}
+	return ""
+}
+
+func isTagName(name string) bool {
+	if name == "*" {
+		return true
+	}
+	if name == "" {
+		return false
+	}
+	for _, character := range name {
+		if (character < 'a' || character > 'z') && (character < '0' || character > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func advance(text string, line int, column int) (int, int) {
+	for _, character := range text {
+		if character == '\n' {
+			line, column = line+1, 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
@@ -0,0 +1,66 @@
+package bbcode
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		want      []Issue
+	}{
+		{"valid", "[b]bold[/b] [url=https://example.com]link[/url]\n[list][*]item[/list]", 100, nil},
+		{"bracketed text", "Version [1.0.1]", 100, nil},
+		{"too long", "text", 3, []Issue{
+			{0, 0, SeverityError, "text is 4 bytes long, steam allows at most 3"},
+		}},
+		{"unknown tag", "[color=red]x[/color]", 100, []Issue{
+			{1, 1, SeverityWarning, "unknown tag [color=red] is shown as text"},
+			{1, 13, SeverityWarning, "unknown tag [/color] is shown as text"},
+		}},
+		{"never closed", "text\n[b]bold", 100, []Issue{
+			{2, 1, SeverityError, "tag [b] is never closed"},
+		}},
+		{"not closed before", "[b][i]x[/b][/i]", 100, []Issue{
+			{1, 4, SeverityError, "tag [i] is not closed before [/b]"},
+			{1, 12, SeverityError, "closing tag [/i] without opening tag"},
+		}},
+		{"closing without opening", "x[/b]", 100, []Issue{
+			{1, 2, SeverityError, "closing tag [/b] without opening tag"},
+		}},
+		{"case insensitive", "[B]x[/b]", 100, nil},
+		{"noparse", "[noparse][b][/noparse]", 100, nil},
+		{"code", "[code]\n[i]\n[/code]\n[b]", 100, []Issue{
+			{4, 1, SeverityError, "tag [b] is never closed"},
+		}},
+		{"relative link", "[url=/mods]x[/url]", 100, []Issue{
+			{1, 1, SeverityError, "malformed link target '/mods': links need an absolute url like https://example.com"},
+		}},
+		{"empty link", "[url=]x[/url]", 100, []Issue{
+			{1, 1, SeverityError, "link [url=] has no target"},
+		}},
+		{"column counts characters", "äö[/b]", 100, []Issue{
+			{1, 3, SeverityError, "closing tag [/b] without opening tag"},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Lint(test.text, test.maxLength)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Lint(%q) =\n%s\nwant\n%s", test.text, formatIssues(got), formatIssues(test.want))
+			}
+		})
+	}
+}
+
+func formatIssues(issues []Issue) string {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,366 @@
+package bbcode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MarkdownExtension marks description and change note files that are converted to steam bbcode.
+const MarkdownExtension = ".md"
+
+var (
+	headingPattern  = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	rulePattern     = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	fencePattern    = regexp.MustCompile("^\\s*(```|~~~)")
+	listItemPattern = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	tableRowPattern = regexp.MustCompile(`^\s*\|.*\|\s*$`)
+	tableSeparator  = regexp.MustCompile(`^\s*\|?(\s*:?-+:?\s*\|)+\s*(:?-+:?\s*)?$`)
+	quotePattern    = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	codeSpanPattern = regexp.MustCompile("`([^`]+)`")
+	// Link targets may contain balanced parentheses, e.g. https://en.wikipedia.org/wiki/Foo_(bar)
+	imagePattern     = regexp.MustCompile(`!\[([^\]]*)\]\(((?:[^()\s]|\([^()\s]*\))+)(?:\s+"[^"]*")?\)`)
+	linkPattern      = regexp.MustCompile(`\[([^\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)(?:\s+"[^"]*")?\)`)
+	autoLinkPattern  = regexp.MustCompile(`<(https?://[^>\s]+)>`)
+	escapePattern    = regexp.MustCompile(`\\([\\` + "`" + `*_{}\[\]()#+\-.!|~>])`)
+	placeholderRegex = regexp.MustCompile("\x00(\\d+)\x00")
+)
+
+// FromMarkdown converts markdown to steam bbcode.
+// It supports headings, lists, bold, italic and struck through text, links, images,
+// code, quotes, horizontal rules and tables.
+func FromMarkdown(markdown string) string {
+	converter := &markdownConverter{
+		lines: strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n"),
+	}
+	converter.convert()
+	return strings.TrimRight(converter.output.String(), "\n")
+}
+
+type markdownConverter struct {
+	lines  []string
+	index  int
+	output strings.Builder
+}
+
+type listLevel struct {
+	indent  int
+	ordered bool
+}
+
+func (converter *markdownConverter) convert() {
+	for converter.index < len(converter.lines) {
+		line := converter.lines[converter.index]
+
+		switch {
+		case fencePattern.MatchString(line):
+			converter.convertCode()
+		case headingPattern.MatchString(line):
+			match := headingPattern.FindStringSubmatch(line)
+			level := min(len(match[1]), 3)
+			converter.writeLine(fmt.Sprintf("[h%d]%s[/h%d]", level, convertInline(match[2]), level))
+			converter.index++
+		case rulePattern.MatchString(line):
+			converter.writeLine("[hr][/hr]")
+			converter.index++
+		case listItemPattern.MatchString(line):
+			converter.convertList()
+		case quotePattern.MatchString(line):
+			converter.convertQuote()
+		case tableRowPattern.MatchString(line) && converter.index+1 < len(converter.lines) && tableSeparator.MatchString(converter.lines[converter.index+1]):
+			converter.convertTable()
+		default:
+			converter.writeLine(convertInline(line))
+			converter.index++
+		}
+	}
+}
+
+func (converter *markdownConverter) writeLine(line string) {
+	converter.output.WriteString(line)
+	converter.output.WriteString("\n")
+}
+
+func (converter *markdownConverter) convertCode() {
+	fence := fencePattern.FindStringSubmatch(converter.lines[converter.index])[1]
+	converter.index++
+
+	converter.writeLine("[code]")
+	for converter.index < len(converter.lines) {
+		line := converter.lines[converter.index]
+		converter.index++
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			break
+		}
+		converter.writeLine(line)
+	}
+	converter.writeLine("[/code]")
+}
+
+func (converter *markdownConverter) convertList() {
+	var levels []listLevel
+	closeLevel := func() {
+		if levels[len(levels)-1].ordered {
+			converter.writeLine("[/olist]")
+		} else {
+			converter.writeLine("[/list]")
+		}
+		levels = levels[:len(levels)-1]
+	}
+
+	for converter.index < len(converter.lines) {
+		match := listItemPattern.FindStringSubmatch(converter.lines[converter.index])
+		if match == nil || rulePattern.MatchString(converter.lines[converter.index]) {
+			break
+		}
+		indent := len(strings.ReplaceAll(match[1], "\t", "    "))
+		ordered := match[2] != "-" && match[2] != "*" && match[2] != "+"
+
+		for len(levels) > 0 && indent < levels[len(levels)-1].indent {
+			closeLevel()
+		}
+		if len(levels) > 0 && indent == levels[len(levels)-1].indent && ordered != levels[len(levels)-1].ordered {
+			closeLevel()
+		}
+		if len(levels) == 0 || indent > levels[len(levels)-1].indent {
+			levels = append(levels, listLevel{indent: indent, ordered: ordered})
+			if ordered {
+				converter.writeLine("[olist]")
+			} else {
+				converter.writeLine("[list]")
+			}
+		}
+
+		converter.writeLine("[*]" + convertInline(match[3]))
+		converter.index++
+	}
+
+	for len(levels) > 0 {
+		closeLevel()
+	}
+}
+
+func (converter *markdownConverter) convertQuote() {
+	var quoted []string
+	for converter.index < len(converter.lines) {
+		match := quotePattern.FindStringSubmatch(converter.lines[converter.index])
+		if match == nil {
+			break
+		}
+		quoted = append(quoted, match[1])
+		converter.index++
+	}
+
+	converter.writeLine("[quote]" + FromMarkdown(strings.Join(quoted, "\n")) + "[/quote]")
+}
+
+func (converter *markdownConverter) convertTable() {
+	converter.writeLine("[table]")
+	converter.writeTableRow(converter.lines[converter.index], "th")
+	converter.index += 2
+
+	for converter.index < len(converter.lines) && tableRowPattern.MatchString(converter.lines[converter.index]) {
+		converter.writeTableRow(converter.lines[converter.index], "td")
+		converter.index++
+	}
+	converter.writeLine("[/table]")
+}
+
+func (converter *markdownConverter) writeTableRow(line string, cellTag string) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+
+	var row strings.Builder
+	row.WriteString("[tr]")
+	for _, cell := range splitTableCells(line) {
+		row.WriteString("[" + cellTag + "]" + convertInline(strings.TrimSpace(cell)) + "[/" + cellTag + "]")
+	}
+	row.WriteString("[/tr]")
+	converter.writeLine(row.String())
+}
+
+// splitTableCells splits a table row at every pipe that is not escaped.
+func splitTableCells(line string) []string {
+	var cells []string
+	var cell strings.Builder
+	for index := 0; index < len(line); index++ {
+		if line[index] == '\\' && index+1 < len(line) && line[index+1] == '|' {
+			cell.WriteByte('|')
+			index++
+			continue
+		}
+		if line[index] == '|' {
+			cells = append(cells, cell.String())
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(line[index])
+	}
+	return append(cells, cell.String())
+}
+
+// convertInline converts the inline formatting of a single line.
+// Code, links, images and escaped characters are replaced by placeholders first,
+// so their content is not formatted.
+func convertInline(text string) string {
+	var placeholders []string
+	hold := func(bbcode string) string {
+		placeholders = append(placeholders, bbcode)
+		return "\x00" + strconv.Itoa(len(placeholders)-1) + "\x00"
+	}
+
+	text = codeSpanPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return hold("[noparse]" + codeSpanPattern.FindStringSubmatch(match)[1] + "[/noparse]")
+	})
+	text = escapePattern.ReplaceAllStringFunc(text, func(match string) string {
+		return hold(match[1:])
+	})
+	text = imagePattern.ReplaceAllStringFunc(text, func(match string) string {
+		return hold("[img]" + imagePattern.FindStringSubmatch(match)[2] + "[/img]")
+	})
+	// The link text is formatted on its own, so its tags do not overlap the link
+	text = linkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := linkPattern.FindStringSubmatch(match)
+		return hold("[url=" + groups[2] + "]" + convertEmphasis(groups[1]) + "[/url]")
+	})
+	text = autoLinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		url := autoLinkPattern.FindStringSubmatch(match)[1]
+		return hold("[url=" + url + "]" + url + "[/url]")
+	})
+
+	text = convertEmphasis(text)
+
+	// Placeholders may contain other placeholders
+	for placeholderRegex.MatchString(text) {
+		text = placeholderRegex.ReplaceAllStringFunc(text, func(match string) string {
+			index, _ := strconv.Atoi(placeholderRegex.FindStringSubmatch(match)[1])
+			return placeholders[index]
+		})
+	}
+	return text
+}
+
+// emphasisRun is a run of the same emphasis delimiter, e.g. "**" or "~~", or plain text if char is 0.
+type emphasisRun struct {
+	char byte
+	text string
+	// count is the number of delimiters that are not matched and are kept as text
+	count    int
+	canOpen  bool
+	canClose bool
+	// opening and closing are the tags of the matched delimiters, ordered as they are written
+	opening []string
+	closing []string
+}
+
+// convertEmphasis replaces bold, italic and struck through text with tags.
+// Like in CommonMark, a closing delimiter matches the nearest opening delimiter of the same kind
+// and the delimiters in between are kept as text, so the tags are always properly nested.
+func convertEmphasis(text string) string {
+	runs := splitEmphasisRuns(text)
+
+	var openers []*emphasisRun
+	for _, run := range runs {
+		if run.char == 0 {
+			continue
+		}
+		for run.canClose && run.count > 0 {
+			found := -1
+			for index := len(openers) - 1; index >= 0; index-- {
+				if openers[index].char == run.char {
+					found = index
+					break
+				}
+			}
+			if found < 0 {
+				break
+			}
+
+			opener := openers[found]
+			used, tag := 1, "i"
+			switch {
+			case run.char == '~':
+				used, tag = 2, "strike"
+			case opener.count >= 2 && run.count >= 2:
+				used, tag = 2, "b"
+			}
+			// Delimiters closer to the text are matched first, so later tags are the outer ones
+			opener.opening = append([]string{"[" + tag + "]"}, opener.opening...)
+			run.closing = append(run.closing, "[/"+tag+"]")
+			opener.count -= used
+			run.count -= used
+
+			openers = openers[:found+1]
+			if opener.count == 0 {
+				openers = openers[:found]
+			}
+		}
+		if run.canOpen && run.count > 0 {
+			openers = append(openers, run)
+		}
+	}
+
+	var builder strings.Builder
+	for _, run := range runs {
+		if run.char == 0 {
+			builder.WriteString(run.text)
+			continue
+		}
+		builder.WriteString(strings.Join(run.closing, ""))
+		builder.WriteString(strings.Repeat(string(run.char), run.count))
+		builder.WriteString(strings.Join(run.opening, ""))
+	}
+	return builder.String()
+}
+
+// splitEmphasisRuns splits the text into plain text and runs of *, _ or ~~ delimiters.
+// Delimiters can open emphasis if they are followed by text and close it if they follow text,
+// underscores only at word boundaries.
+func splitEmphasisRuns(text string) []*emphasisRun {
+	var runs []*emphasisRun
+	start := 0
+	for index := 0; index < len(text); {
+		char := text[index]
+		if char != '*' && char != '_' && char != '~' {
+			index++
+			continue
+		}
+		end := index
+		for end < len(text) && text[end] == char {
+			end++
+		}
+		if char == '~' && end-index != 2 {
+			index = end
+			continue
+		}
+
+		if start < index {
+			runs = append(runs, &emphasisRun{text: text[start:index]})
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:index])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		run := &emphasisRun{
+			char:     char,
+			count:    end - index,
+			canOpen:  end < len(text) && !unicode.IsSpace(after),
+			canClose: index > 0 && !unicode.IsSpace(before),
+		}
+		if char == '_' {
+			run.canOpen = run.canOpen && (index == 0 || !isWordRune(before))
+			run.canClose = run.canClose && (end == len(text) || !isWordRune(after))
+		}
+		runs = append(runs, run)
+		index, start = end, end
+	}
+	if start < len(text) {
+		runs = append(runs, &emphasisRun{text: text[start:]})
+	}
+	return runs
+}
+
+func isWordRune(character rune) bool {
+	return unicode.IsLetter(character) || unicode.IsDigit(character) || character == '_'
+}
@@ -0,0 +1,55 @@
+package bbcode
+
+import "testing"
+
+func TestFromMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"bold", "**bold** and __bold__", "[b]bold[/b] and [b]bold[/b]"},
+		{"italic", "*italic* and _italic_", "[i]italic[/i] and [i]italic[/i]"},
+		{"strike", "~~struck~~", "[strike]struck[/strike]"},
+		{"bold italic", "***bold italic***", "[i][b]bold italic[/b][/i]"},
+		{"bold italic underscores", "___x___", "[i][b]x[/b][/i]"},
+		{"italic in bold", "**bold *italic* bold**", "[b]bold [i]italic[/i] bold[/b]"},
+		{"bold in italic", "*italic **bold** italic*", "[i]italic [b]bold[/b] italic[/i]"},
+		{"overlapping", "**x ~~y**~~", "[b]x ~~y[/b]~~"},
+		{"unmatched", "**bold", "**bold"},
+		{"spaced asterisks", "2 * 3 * 4", "2 * 3 * 4"},
+		{"intraword underscores", "snake_case_name", "snake_case_name"},
+		{"escaped", `\*not italic\*`, "*not italic*"},
+		{"code", "`**code**`", "[noparse]**code**[/noparse]"},
+		{"link", "[wiki](https://example.com)", "[url=https://example.com]wiki[/url]"},
+		{"link with parentheses", "[wiki](https://en.wikipedia.org/wiki/Foo_(bar))", "[url=https://en.wikipedia.org/wiki/Foo_(bar)]wiki[/url]"},
+		{"link in parentheses", "(see [wiki](https://example.com))", "(see [url=https://example.com]wiki[/url])"},
+		{"bold link", "**[wiki](https://example.com)**", "[b][url=https://example.com]wiki[/url][/b]"},
+		{"emphasis in link", "[*wiki*](https://example.com)", "[url=https://example.com][i]wiki[/i][/url]"},
+		{"emphasis across link", "*a [b* c](https://example.com)", "*a [url=https://example.com]b* c[/url]"},
+		{"image", "![logo](https://example.com/logo_(1).png)", "[img]https://example.com/logo_(1).png[/img]"},
+		{"auto link", "<https://example.com>", "[url=https://example.com]https://example.com[/url]"},
+		{"heading", "# Title", "[h1]Title[/h1]"},
+		{"small heading", "#### Title", "[h3]Title[/h3]"},
+		{"rule", "---", "[hr][/hr]"},
+		{"list", "- a\n- b", "[list]\n[*]a\n[*]b\n[/list]"},
+		{"ordered list", "1. a\n2. b", "[olist]\n[*]a\n[*]b\n[/olist]"},
+		{"nested list", "- a\n  - b\n- c", "[list]\n[*]a\n[list]\n[*]b\n[/list]\n[*]c\n[/list]"},
+		{"quote", "> quoted\n> text", "[quote]quoted\ntext[/quote]"},
+		{"code block", "```\n**code**\n```", "[code]\n**code**\n[/code]"},
+		{"table", "| a | b |\n|---|---|\n| 1 | 2 |", "[table]\n[tr][th]a[/th][th]b[/th][/tr]\n[tr][td]1[/td][td]2[/td][/tr]\n[/table]"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := FromMarkdown(test.markdown)
+			if got != test.want {
+				t.Errorf("FromMarkdown(%q) = %q, want %q", test.markdown, got, test.want)
+			}
+			for _, issue := range Lint(got, MaxDescriptionLength) {
+				if issue.Severity == SeverityError {
+					t.Errorf("FromMarkdown(%q) = %q has lint error %s", test.markdown, got, issue)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/manager"
+)
+
+// bumpVersions increases the version of the selected mods before they are uploaded.
+// No version is changed unless all selected mods can be bumped.
+func bumpVersions(applicationConfig *config.ApplicationConfig, modId uint64, part manager.VersionPart) error {
+	var mods []*config.ModConfig
+	for _, mod := range applicationConfig.Mods {
+		if modId == AllMods || mod.Identifier == modId {
+			mods = append(mods, mod)
+		}
+	}
+
+	versions, err := manager.BumpVersions(mods, part)
+	if err != nil {
+		logging.Errorf("%v, no version was bumped", err)
+		return err
+	}
+	for index, mod := range mods {
+		logging.Infof(" - Bumped version of mod %d (%s) to %s", mod.Identifier, mod.Directory, versions[index])
+	}
+	return nil
+}
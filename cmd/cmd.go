@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
 
 	"bahmut.de/pdx-workshop-manager/config"
 	"bahmut.de/pdx-workshop-manager/logging"
@@ -11,7 +15,57 @@ import (
 
 const AllMods uint64 = 0
 
-func Run(configFile string, modId uint64) error {
+type Options struct {
+	ConfigFile string
+	ModId      uint64
+	Visibility steam.Visibility
+	DryRun     bool
+	// Lint checks titles, descriptions and change notes without uploading
+	Lint bool
+	// Bump increases the major, minor or patch version of the selected mods before uploading
+	Bump manager.VersionPart
+	// MetadataOnly updates names, descriptions, tags, thumbnails and visibility without uploading content
+	MetadataOnly bool
+	// Delete deletes the workshop item of the selected mod instead of uploading it
+	Delete bool
+	// Confirm has to be set to delete a workshop item
+	Confirm bool
+}
+
+func Run(options Options) error {
+	configFile := options.ConfigFile
+	modId := options.ModId
+
+	if options.Visibility != "" && !options.Visibility.IsValid() {
+		logging.Errorf("Invalid visibility: %s", options.Visibility)
+		return fmt.Errorf("invalid visibility: %s", options.Visibility)
+	}
+
+	if options.Bump != "" && !options.Bump.IsValid() {
+		logging.Errorf("Invalid version part: %s", options.Bump)
+		return fmt.Errorf("invalid version part: %s", options.Bump)
+	}
+
+	if options.Bump != "" && (options.DryRun || options.Lint || options.MetadataOnly) {
+		logging.Error("A version bump can only be combined with a full upload")
+		return errors.New("a version bump can only be combined with a full upload")
+	}
+
+	if options.Delete && (options.DryRun || options.Lint || options.MetadataOnly || options.Bump != "" || options.Visibility != "") {
+		logging.Error("Deleting a mod can not be combined with other operations")
+		return errors.New("deleting a mod can not be combined with other operations")
+	}
+
+	if options.Delete && modId == AllMods {
+		logging.Error("Select the mod to delete using -mod")
+		return errors.New("no mod selected to delete")
+	}
+
+	if options.Delete && !options.Confirm {
+		logging.Errorf("Deleting workshop item %d can not be undone, add -confirm to delete it", modId)
+		return fmt.Errorf("deleting workshop item %d requires confirmation", modId)
+	}
+
 	logging.Infof("Loading configuration: %s", configFile)
 	applicationConfig, err := config.LoadConfig(configFile)
 	if err != nil {
@@ -19,13 +73,46 @@ func Run(configFile string, modId uint64) error {
 		return err
 	}
 
+	if options.Lint {
+		return lint(applicationConfig, modId)
+	}
+
+	if options.DryRun {
+		return dryRun(applicationConfig, modId, options.Visibility)
+	}
+
+	// The visibility option only applies to this upload, the configured visibility is kept
+	uploadOptions := manager.UploadOptions{Mode: manager.UploadFull, Visibility: options.Visibility}
+	if options.MetadataOnly {
+		logging.Info("Updating metadata only, mod content is not uploaded")
+		uploadOptions.Mode = manager.UploadMetadataOnly
+	}
+
+	manager.SubscribeProgress(renderProgress)
+
 	logging.Info("Initializing Steam")
 	err = manager.Init(applicationConfig)
-	defer steam.SteamAPI_Shutdown()
 	if err != nil {
 		logging.Errorf("Failed to initialize steam: %v", err)
 		return err
 	}
+	defer manager.Shutdown()
+
+	// Ctrl-C stops the running steam call, finished uploads are kept in the manifest
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if options.Delete {
+		return deleteMod(ctx, applicationConfig, modId)
+	}
+
+	if options.Bump != "" {
+		logging.Infof("Bumping %s version", options.Bump)
+		err = bumpVersions(applicationConfig, modId, options.Bump)
+		if err != nil {
+			return err
+		}
+	}
 
 	if modId > AllMods {
 		logging.Infof("Start uploading mod %d", modId)
@@ -34,7 +121,7 @@ func Run(configFile string, modId uint64) error {
 			logging.Errorf("Failed to find mod %d", modId)
 			return fmt.Errorf("failed to find mod %d", modId)
 		}
-		err = manager.UploadMod(applicationConfig, mod)
+		err = manager.UploadMod(ctx, applicationConfig, mod, uploadOptions)
 		if err != nil {
 			logging.Errorf("Failed to upload mod %d: %v", modId, err)
 			return err
@@ -42,15 +129,21 @@ func Run(configFile string, modId uint64) error {
 		logging.Infof("Finished uploading mod: %d", modId)
 	} else {
 		logging.Info("Uploading all mods")
-		for _, mod := range applicationConfig.Mods {
+		for index, mod := range applicationConfig.Mods {
+			if ctx.Err() != nil {
+				logging.Error("Upload was interrupted")
+				logUploadSummary(applicationConfig.Mods[:index], applicationConfig.Mods[index:])
+				return ctx.Err()
+			}
 			if mod.Identifier == 0 {
 				logging.Infof(" - Start uploading new mod")
 			} else {
 				logging.Infof(" - Start uploading mod: %d", mod.Identifier)
 			}
-			err = manager.UploadMod(applicationConfig, mod)
+			err = manager.UploadMod(ctx, applicationConfig, mod, uploadOptions)
 			if err != nil {
-				logging.Errorf("Failed to upload mod %d: %v", modId, err)
+				logging.Errorf("Failed to upload mod %d (%s): %v", mod.Identifier, mod.Directory, err)
+				logUploadSummary(applicationConfig.Mods[:index], applicationConfig.Mods[index:])
 				return err
 			}
 			logging.Infof(" - Finished uploading mod: %d", mod.Identifier)
@@ -60,3 +153,13 @@ func Run(configFile string, modId uint64) error {
 
 	return nil
 }
+
+// logUploadSummary lists which mods were uploaded before an upload of all mods failed.
+func logUploadSummary(uploaded []*config.ModConfig, remaining []*config.ModConfig) {
+	for _, mod := range uploaded {
+		logging.Infof(" - Uploaded: %d (%s)", mod.Identifier, mod.Directory)
+	}
+	for _, mod := range remaining {
+		logging.Infof(" - Not uploaded: %d (%s)", mod.Identifier, mod.Directory)
+	}
+}
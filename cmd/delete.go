@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/manager"
+)
+
+// deleteMod deletes the workshop item of the selected mod and resets its configured id.
+func deleteMod(ctx context.Context, applicationConfig *config.ApplicationConfig, modId uint64) error {
+	mod := applicationConfig.GetModByIdentifier(modId)
+	if mod == nil {
+		logging.Errorf("Failed to find mod %d", modId)
+		return fmt.Errorf("failed to find mod %d", modId)
+	}
+
+	logging.Infof("Deleting workshop item %d (%s)", modId, mod.Directory)
+	err := manager.DeleteMod(ctx, applicationConfig, mod)
+	if err != nil {
+		logging.Errorf("Failed to delete mod %d: %v", modId, err)
+		return err
+	}
+	logging.Infof("Deleted workshop item %d, the id of the mod was reset to 0", modId)
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/manager"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// dryRun prepares the upload data of the selected mods and reports it
+// without initializing or calling steam.
+func dryRun(applicationConfig *config.ApplicationConfig, modId uint64, visibility steam.Visibility) error {
+	mods := applicationConfig.Mods
+	if modId > AllMods {
+		mod := applicationConfig.GetModByIdentifier(modId)
+		if mod == nil {
+			logging.Errorf("Failed to find mod %d", modId)
+			return fmt.Errorf("failed to find mod %d", modId)
+		}
+		mods = []*config.ModConfig{mod}
+	}
+
+	logging.Info("Dry run, nothing will be sent to steam")
+	var errs []error
+	for _, mod := range mods {
+		logging.Infof(" - Checking mod: %d (%s)", mod.Identifier, mod.Directory)
+		data, err := manager.PrepareMod(applicationConfig, mod)
+		if err != nil {
+			logging.Errorf("Failed to prepare mod %d: %v", mod.Identifier, err)
+			errs = append(errs, fmt.Errorf("mod %d: %w", mod.Identifier, err))
+			continue
+		}
+		if visibility != "" {
+			data.Visibility = visibility
+		}
+		reportUploadData(data)
+	}
+	return errors.Join(errs...)
+}
+
+func reportUploadData(data *manager.ModUploadData) {
+	thumbnailPath, err := filepath.Abs(data.Thumbnail)
+	if err != nil {
+		thumbnailPath = data.Thumbnail
+	}
+
+	logging.Infof("   Content: %s (%d files)", data.ContentPath, len(data.ContentFiles))
+	if data.ConvertThumbnail {
+		logging.Infof("   Thumbnail: %s (converted to jpeg before the upload)", thumbnailPath)
+	} else {
+		logging.Infof("   Thumbnail: %s", thumbnailPath)
+	}
+	logging.Infof("   Version: %s", data.Metadata.Version)
+	logging.Infof("   Tags: %s", strings.Join(data.Metadata.Tags, ", "))
+	if data.MinGameBranch != "" || data.MaxGameBranch != "" {
+		logging.Infof("   Game branches: %s - %s", data.MinGameBranch, data.MaxGameBranch)
+	}
+	for _, image := range data.GalleryImages {
+		logging.Infof("   Gallery image: %s", image)
+	}
+	for _, video := range data.Videos {
+		logging.Infof("   Gallery video: %s", video)
+	}
+	for _, dependency := range data.Dependencies {
+		logging.Infof("   Dependency: %d", dependency)
+	}
+	for _, app := range data.Config.AppDependencies {
+		logging.Infof("   App dependency: %d", app)
+	}
+	for _, key := range slices.Sorted(maps.Keys(data.KeyValueTags)) {
+		logging.Infof("   Key-value tag: %s = %s", key, data.KeyValueTags[key])
+	}
+	if data.ItemMetadata != "" {
+		logging.Infof("   Metadata: %s", data.ItemMetadata)
+	}
+	if data.Visibility != "" {
+		logging.Infof("   Visibility: %s", data.Visibility)
+	}
+
+	for _, issue := range data.LintIssues {
+		logging.Warnf("   %s", issue)
+	}
+
+	languages := map[steam.ApiLanguage]bool{}
+	for language := range data.Names {
+		languages[language] = true
+	}
+	for language := range data.Descriptions {
+		languages[language] = true
+	}
+	for language := range data.ChangeNotes {
+		languages[language] = true
+	}
+
+	for _, language := range slices.Sorted(maps.Keys(languages)) {
+		logging.Infof("   [%s]", language)
+		if name, ok := data.Names[language]; ok {
+			logging.Infof("     Title: %s", name)
+		}
+		if description, ok := data.Descriptions[language]; ok {
+			logging.Infof("     Description:\n%s", description)
+		}
+		if changeNote, ok := data.ChangeNotes[language]; ok {
+			logging.Infof("     Change note:\n%s", changeNote)
+		}
+	}
+}
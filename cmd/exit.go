@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"bahmut.de/pdx-workshop-manager/manager"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// Exit codes of the command line depending on why it failed.
+const (
+	ExitSuccess = 0
+	ExitFailure = 1
+	// ExitInvalidMod means the files or configuration of a mod are invalid and nothing was uploaded
+	ExitInvalidMod = 2
+	// ExitRejected means steam rejected the upload
+	ExitRejected = 3
+	// ExitUnavailable means steam was temporarily unavailable, running again later may succeed
+	ExitUnavailable = 75
+	// ExitInterrupted means the upload was cancelled using Ctrl-C
+	ExitInterrupted = 130
+)
+
+// ExitCode returns the exit code for the error returned by Run.
+func ExitCode(err error) int {
+	var invalidMod *manager.InvalidModError
+	var resultError *steam.ResultError
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	case errors.As(err, &invalidMod), errors.Is(err, manager.ErrNotPublished):
+		return ExitInvalidMod
+	case errors.Is(err, manager.ErrCallTimeout):
+		return ExitUnavailable
+	case errors.As(err, &resultError):
+		if resultError.Retryable() {
+			return ExitUnavailable
+		}
+		return ExitRejected
+	case errors.Is(err, manager.ErrItemNotFound), errors.Is(err, manager.ErrWrongGame), errors.Is(err, manager.ErrLegalAgreement):
+		return ExitRejected
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"bahmut.de/pdx-workshop-manager/bbcode"
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/manager"
+)
+
+// lint checks the titles, descriptions and change notes of the selected mods
+// without initializing or calling steam.
+func lint(applicationConfig *config.ApplicationConfig, modId uint64) error {
+	mods := applicationConfig.Mods
+	if modId > AllMods {
+		mod := applicationConfig.GetModByIdentifier(modId)
+		if mod == nil {
+			logging.Errorf("Failed to find mod %d", modId)
+			return fmt.Errorf("failed to find mod %d", modId)
+		}
+		mods = []*config.ModConfig{mod}
+	}
+
+	var errs []error
+	for _, mod := range mods {
+		logging.Infof(" - Linting mod: %d (%s)", mod.Identifier, mod.Directory)
+		data, err := manager.PrepareMod(applicationConfig, mod)
+
+		var lintError *manager.LintError
+		switch {
+		case errors.As(err, &lintError):
+			reportLintIssues(lintError.Issues)
+			errs = append(errs, fmt.Errorf("mod %d: %w", mod.Identifier, err))
+		case err != nil:
+			logging.Errorf("Failed to prepare mod %d: %v", mod.Identifier, err)
+			errs = append(errs, fmt.Errorf("mod %d: %w", mod.Identifier, err))
+		default:
+			reportLintIssues(data.LintIssues)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func reportLintIssues(issues []manager.LintIssue) {
+	if len(issues) == 0 {
+		logging.Info("   No issues found")
+		return
+	}
+	for _, issue := range issues {
+		if issue.Severity == bbcode.SeverityError {
+			logging.Errorf("   %s", issue)
+		} else {
+			logging.Warnf("   %s", issue)
+		}
+	}
+}
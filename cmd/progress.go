@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/manager"
+)
+
+var progressShown = false
+
+// renderProgress keeps a single updating progress line
+// on the terminal while an update is submitted to steam.
+func renderProgress(progress manager.UploadProgress) {
+	if progress.Finished {
+		if progressShown {
+			fmt.Println()
+		}
+		progressShown = false
+		return
+	}
+
+	progressShown = true
+	fmt.Printf(
+		"\r%s   [%s] %-40s %s / %s (%d%%)",
+		logging.AnsiClearLine,
+		progress.Language,
+		progress.StatusDescription(),
+		formatBytes(progress.BytesProcessed),
+		formatBytes(progress.BytesTotal),
+		progress.Percentage(),
+	)
+}
+
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	divisor, exponent := uint64(unit), 0
+	for remaining := bytes / unit; remaining >= unit; remaining /= unit {
+		divisor *= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(divisor), "KMGTPE"[exponent])
+}
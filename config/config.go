@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"bahmut.de/pdx-workshop-manager/logging"
 	"bahmut.de/pdx-workshop-manager/steam"
@@ -14,34 +17,65 @@ const (
 )
 
 type ApplicationConfig struct {
-	configFilePath string
-	Game           uint         `json:"game"`
-	Mods           []*ModConfig `json:"mods"`
+	configFilePath   string
+	Game             uint         `json:"game"`
+	IncludeDirectory string       `json:"include-directory,omitempty"`
+	RetryAttempts    uint         `json:"retry-attempts,omitempty"`
+	CallTimeout      string       `json:"call-timeout,omitempty"`
+	Mods             []*ModConfig `json:"mods"`
 }
 
 type ApplicationConfigJson struct {
-	Game uint             `json:"game"`
-	Mods []*ModConfigJson `json:"mods"`
+	Game             uint             `json:"game"`
+	IncludeDirectory string           `json:"include-directory"`
+	RetryAttempts    uint             `json:"retry-attempts"`
+	CallTimeout      string           `json:"call-timeout"`
+	Mods             []*ModConfigJson `json:"mods"`
 }
 
 type ModConfig struct {
 	Identifier            uint64                       `json:"id"`
 	Directory             string                       `json:"directory"`
 	Thumbnail             string                       `json:"thumbnail"`
+	ConvertThumbnail      bool                         `json:"convert-thumbnail,omitempty"`
+	Visibility            steam.Visibility             `json:"visibility,omitempty"`
+	Gallery               string                       `json:"gallery,omitempty"`
+	Videos                []string                     `json:"videos,omitempty"`
+	Dependencies          []uint64                     `json:"dependencies"`
+	AppDependencies       []uint                       `json:"app-dependencies"`
+	KeyValueTags          map[string]string            `json:"key-value-tags"`
+	Metadata              string                       `json:"metadata,omitempty"`
+	Exclude               []string                     `json:"exclude,omitempty"`
+	MinGameBranch         string                       `json:"min-game-branch,omitempty"`
+	MaxGameBranch         string                       `json:"max-game-branch,omitempty"`
 	Names                 map[steam.ApiLanguage]string `json:"names"`
 	Descriptions          map[steam.ApiLanguage]string `json:"descriptions"`
 	ChangeNoteDirectories map[steam.ApiLanguage]string `json:"change-note-directories"`
+	Changelogs            map[steam.ApiLanguage]string `json:"changelogs,omitempty"`
 }
 
 type ModConfigJson struct {
 	Identifier            uint64                       `json:"id"`
 	Directory             string                       `json:"directory"`
 	Thumbnail             string                       `json:"thumbnail"`
+	ConvertThumbnail      bool                         `json:"convert-thumbnail"`
+	Visibility            steam.Visibility             `json:"visibility"`
+	Gallery               string                       `json:"gallery"`
+	Videos                []string                     `json:"videos"`
+	Dependencies          []uint64                     `json:"dependencies"`
+	AppDependencies       []uint                       `json:"app-dependencies"`
+	KeyValueTags          map[string]string            `json:"key-value-tags"`
+	Metadata              string                       `json:"metadata"`
+	Exclude               []string                     `json:"exclude"`
+	MinGameBranch         string                       `json:"min-game-branch"`
+	MaxGameBranch         string                       `json:"max-game-branch"`
 	Names                 map[steam.ApiLanguage]string `json:"names"`
 	Descriptions          map[steam.ApiLanguage]string `json:"descriptions"`
 	Description           string                       `json:"description"`
 	ChangeNoteDirectories map[steam.ApiLanguage]string `json:"change-note-directories"`
 	ChangeNoteDirectory   string                       `json:"change-note-directory"`
+	Changelogs            map[steam.ApiLanguage]string `json:"changelogs"`
+	Changelog             string                       `json:"changelog"`
 }
 
 func LoadConfig(path string) (*ApplicationConfig, error) {
@@ -66,9 +100,16 @@ func LoadConfig(path string) (*ApplicationConfig, error) {
 	}
 
 	config := &ApplicationConfig{
-		configFilePath: path,
-		Game:           configJson.Game,
-		Mods:           make([]*ModConfig, len(configJson.Mods)),
+		configFilePath:   path,
+		Game:             configJson.Game,
+		IncludeDirectory: configJson.IncludeDirectory,
+		RetryAttempts:    configJson.RetryAttempts,
+		CallTimeout:      configJson.CallTimeout,
+		Mods:             make([]*ModConfig, len(configJson.Mods)),
+	}
+
+	if _, err := config.ParseCallTimeout(); err != nil {
+		return nil, err
 	}
 
 	for i, configJson := range configJson.Mods {
@@ -76,9 +117,25 @@ func LoadConfig(path string) (*ApplicationConfig, error) {
 			Identifier:            configJson.Identifier,
 			Directory:             configJson.Directory,
 			Thumbnail:             configJson.Thumbnail,
+			ConvertThumbnail:      configJson.ConvertThumbnail,
+			Visibility:            configJson.Visibility,
+			Gallery:               configJson.Gallery,
+			Videos:                configJson.Videos,
+			Dependencies:          configJson.Dependencies,
+			AppDependencies:       configJson.AppDependencies,
+			KeyValueTags:          configJson.KeyValueTags,
+			Metadata:              configJson.Metadata,
+			Exclude:               configJson.Exclude,
+			MinGameBranch:         configJson.MinGameBranch,
+			MaxGameBranch:         configJson.MaxGameBranch,
 			Names:                 configJson.Names,
 			Descriptions:          configJson.Descriptions,
 			ChangeNoteDirectories: configJson.ChangeNoteDirectories,
+			Changelogs:            configJson.Changelogs,
+		}
+
+		if configJson.Visibility != "" && !configJson.Visibility.IsValid() {
+			return nil, fmt.Errorf("invalid visibility '%s' for mod %d", configJson.Visibility, configJson.Identifier)
 		}
 
 		if configJson.Names == nil {
@@ -104,6 +161,19 @@ func LoadConfig(path string) (*ApplicationConfig, error) {
 		if configJson.ChangeNoteDirectory != "" {
 			config.Mods[i].ChangeNoteDirectories[steam.English] = configJson.ChangeNoteDirectory
 		}
+
+		if configJson.Changelog != "" {
+			if config.Mods[i].Changelogs == nil {
+				config.Mods[i].Changelogs = make(map[steam.ApiLanguage]string)
+			}
+			config.Mods[i].Changelogs[steam.English] = configJson.Changelog
+		}
+
+		for language := range config.Mods[i].Changelogs {
+			if _, ok := config.Mods[i].ChangeNoteDirectories[language]; ok {
+				return nil, fmt.Errorf("both a change note directory and a changelog are configured for '%s' of mod %d", language, configJson.Identifier)
+			}
+		}
 	}
 
 	return config, nil
@@ -131,6 +201,24 @@ func (config *ApplicationConfig) GetModByIdentifier(identifier uint64) *ModConfi
 	return nil
 }
 
+// ParseCallTimeout returns the configured call timeout, 0 if none is configured.
+func (config *ApplicationConfig) ParseCallTimeout() (time.Duration, error) {
+	if config.CallTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(config.CallTimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid call-timeout '%s': expected a duration like 10m or 1h30m", config.CallTimeout)
+	}
+	return timeout, nil
+}
+
+// ManifestFilePath returns the path of the upload manifest kept next to the config file,
+// e.g. manager-config.manifest.json for manager-config.json.
+func (config *ApplicationConfig) ManifestFilePath() string {
+	return strings.TrimSuffix(config.configFilePath, filepath.Ext(config.configFilePath)) + ".manifest.json"
+}
+
 func (config *ApplicationConfig) Save() error {
 	content, err := json.MarshalIndent(config, "", "\t")
 	if err != nil {
@@ -20,6 +20,11 @@ const (
 	AnsiLinkSplit = "\x1B\\"
 )
 
+// Cursor
+const (
+	AnsiClearLine = "\x1b[2K"
+)
+
 // Effects
 const (
 	AnsiBoldOn       = "\x1b[1m"
@@ -4,20 +4,37 @@ package main
 
 import (
 	"flag"
+	"os"
 
 	"bahmut.de/pdx-workshop-manager/cmd"
 	"bahmut.de/pdx-workshop-manager/config"
 	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/manager"
+	"bahmut.de/pdx-workshop-manager/steam"
 	"bahmut.de/pdx-workshop-manager/web"
 )
 
 var modId uint64 = 0
 var configFile string
+var visibility string
+var dryRun bool
+var metadataOnly bool
+var lint bool
+var bump string
+var deleteMod bool
+var confirm bool
 
 func parseArgs() int {
 	flag.CommandLine.Init("", flag.ExitOnError)
 	flag.Uint64Var(&modId, "mod", cmd.AllMods, "Configured workshop mod id or 0 for all mods (default 0)")
 	flag.StringVar(&configFile, "config", config.DefaultFileName, "Path to the config file")
+	flag.StringVar(&visibility, "visibility", "", "Workshop visibility to apply to the uploaded mods without changing the config: public, friends-only, unlisted or private")
+	flag.BoolVar(&dryRun, "dry-run", false, "Validate the selected mods and report what would be uploaded without connecting to steam")
+	flag.BoolVar(&metadataOnly, "metadata-only", false, "Only update names, descriptions, tags, thumbnails and visibility without uploading content or adding a change note")
+	flag.BoolVar(&lint, "lint", false, "Check the titles, descriptions and change notes of the selected mods for bbcode errors and steam length limits")
+	flag.StringVar(&bump, "bump", "", "Increase the major, minor or patch version in the metadata.json of the selected mods before uploading")
+	flag.BoolVar(&deleteMod, "delete", false, "Delete the workshop item of the mod selected with -mod for everyone and reset its configured id to 0")
+	flag.BoolVar(&confirm, "confirm", false, "Confirm deleting a workshop item, which can not be undone")
 	flag.Parse()
 	return len(flag.Args())
 }
@@ -26,9 +43,26 @@ func main() {
 	if parseArgs() == 0 {
 		web.Run()
 	} else {
-		err := cmd.Run(configFile, modId)
+		err := cmd.Run(cmd.Options{
+			ConfigFile:   configFile,
+			ModId:        modId,
+			Visibility:   steam.Visibility(visibility),
+			DryRun:       dryRun,
+			MetadataOnly: metadataOnly,
+			Lint:         lint,
+			Bump:         manager.VersionPart(bump),
+			Delete:       deleteMod,
+			Confirm:      confirm,
+		})
 		if err != nil {
 			logging.Errorf("Error: %v", err)
+			os.Exit(cmd.ExitCode(err))
+		} else if lint {
+			logging.Infof("Lint successful")
+		} else if deleteMod {
+			logging.Infof("Delete successful")
+		} else if dryRun {
+			logging.Infof("Dry run successful")
 		} else {
 			logging.Infof("Upload successful")
 		}
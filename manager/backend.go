@@ -0,0 +1,80 @@
+package manager
+
+import (
+	"context"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// WorkshopBackend is the workshop the manager publishes to.
+// SteamBackend talks to the steam client, FakeBackend keeps everything in memory.
+// Calls stop waiting for the workshop once their context is done.
+type WorkshopBackend interface {
+	CreateItem(ctx context.Context, game uint) (uint64, error)
+	SubmitItemUpdate(ctx context.Context, update *ItemUpdate) error
+	QueryItem(ctx context.Context, game uint, identifier uint64) (*ItemDetails, error)
+	AddDependency(ctx context.Context, parent uint64, child uint64) error
+	RemoveDependency(ctx context.Context, parent uint64, child uint64) error
+	GetAppDependencies(ctx context.Context, identifier uint64) ([]uint, error)
+	AddAppDependency(ctx context.Context, identifier uint64, app uint) error
+	RemoveAppDependency(ctx context.Context, identifier uint64, app uint) error
+	DeleteItem(ctx context.Context, identifier uint64) error
+}
+
+// ItemUpdate contains everything sent with a single item update.
+// Empty fields are not changed on the workshop item.
+type ItemUpdate struct {
+	Game        uint
+	Identifier  uint64
+	Language    steam.ApiLanguage
+	Title       string
+	Description string
+	ContentPath string
+	PreviewPath string
+	Tags        []string
+	// KeyValueTags replace all key-value tags of the item, nil keeps them
+	KeyValueTags map[string]string
+	Metadata     string
+	// Game branches the item requires, empty on both keeps them
+	MinGameBranch string
+	MaxGameBranch string
+	Visibility    steam.Visibility
+	ChangeNote    string
+	// Additional previews, indexes refer to ItemDetails.Previews
+	UpdatePreviewFiles map[uint]string
+	RemovePreviews     []uint
+	AddPreviewFiles    []string
+	AddPreviewVideos   []string
+}
+
+// ItemDetails is the current state of a workshop item.
+type ItemDetails struct {
+	Identifier    uint64
+	Title         string
+	Description   string
+	Tags          []string
+	KeyValueTags  map[string]string
+	Metadata      string
+	MinGameBranch string
+	MaxGameBranch string
+	Visibility    steam.Visibility
+	Previews      []ItemPreview
+	// Children are the workshop items this item depends on
+	Children []uint64
+}
+
+// ItemPreview is an additional preview image or video of a workshop item.
+type ItemPreview struct {
+	Index uint
+	Type  steam.EItemPreviewType
+	// Source is the URL of an image or the YouTube video id
+	Source   string
+	FileName string
+}
+
+var backend WorkshopBackend = &SteamBackend{}
+
+// SetBackend replaces the workshop backend used by the manager.
+func SetBackend(workshopBackend WorkshopBackend) {
+	backend = workshopBackend
+}
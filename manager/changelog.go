@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// linkReferencePattern matches link reference definitions like "[1.0.1]: https://...",
+// which follow the last section of a changelog.
+var linkReferencePattern = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+
+// readChangelogSection reads a Keep a Changelog style markdown file
+// and returns the section of the given version.
+func readChangelogSection(path string, version string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	section, ok := changelogSection(string(content), version)
+	if !ok {
+		return "", fmt.Errorf("no section for version %s", version)
+	}
+	return section, nil
+}
+
+// changelogSection returns the content between the level two heading of the version,
+// e.g. "## [1.0.1] - 2024-05-01", and the next level two heading or link reference definition.
+func changelogSection(content string, version string) (string, bool) {
+	if normalizeVersion(version) == "" {
+		return "", false
+	}
+
+	var section []string
+	found := false
+
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(content, "\uFEFF")))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "## ") {
+			if found {
+				break
+			}
+			found = changelogHeadingVersion(line) == normalizeVersion(version)
+			continue
+		}
+		if found && linkReferencePattern.MatchString(line) {
+			break
+		}
+		if found {
+			section = append(section, line)
+		}
+	}
+
+	if !found {
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(section, "\n")), true
+}
+
+// changelogHeadingVersion extracts the version of a heading like "## [1.0.1] - 2024-05-01".
+func changelogHeadingVersion(heading string) string {
+	heading = strings.TrimSpace(strings.TrimPrefix(heading, "## "))
+	if strings.HasPrefix(heading, "[") {
+		if end := strings.Index(heading, "]"); end > 0 {
+			return normalizeVersion(heading[1:end])
+		}
+	}
+	fields := strings.Fields(heading)
+	if len(fields) == 0 {
+		return ""
+	}
+	return normalizeVersion(fields[0])
+}
+
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
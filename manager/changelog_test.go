@@ -0,0 +1,44 @@
+package manager
+
+import "testing"
+
+const testChangelog = `# Changelog
+
+## [Unreleased]
+
+- Work in progress
+
+## [1.1.0] - 2024-05-01
+
+### Added
+- New event
+
+## [v1.0.0] - 2024-04-01
+
+- First release
+
+[Unreleased]: https://github.com/example/mod/compare/v1.1.0...HEAD
+[1.1.0]: https://github.com/example/mod/compare/v1.0.0...v1.1.0
+[v1.0.0]: https://github.com/example/mod/releases/tag/v1.0.0
+`
+
+func TestChangelogSection(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		ok      bool
+	}{
+		{version: "1.1.0", want: "### Added\n- New event", ok: true},
+		{version: "v1.1.0", want: "### Added\n- New event", ok: true},
+		{version: "1.0.0", want: "- First release", ok: true},
+		{version: "Unreleased", want: "- Work in progress", ok: true},
+		{version: "2.0.0", ok: false},
+		{version: "", ok: false},
+	}
+	for _, test := range tests {
+		got, ok := changelogSection(testChangelog, test.version)
+		if ok != test.ok || got != test.want {
+			t.Errorf("changelogSection(%q) = %q, %v, want %q, %v", test.version, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"bahmut.de/pdx-workshop-manager/config"
+)
+
+// DeleteMod deletes the workshop item of a mod for everyone, this can not be undone.
+// The mod stays configured with its id reset to 0, so the next upload creates a new workshop item.
+func DeleteMod(ctx context.Context, appConfig *config.ApplicationConfig, modConfig *config.ModConfig) error {
+	identifier := modConfig.Identifier
+	if identifier == 0 {
+		return fmt.Errorf("failed to delete mod %s: %w", modConfig.Directory, ErrNotPublished)
+	}
+
+	_, err := retry(ctx, fmt.Sprintf("deleting workshop item %d", identifier), func() (struct{}, error) {
+		return struct{}{}, backend.DeleteItem(ctx, identifier)
+	})
+	if err != nil {
+		return &UploadError{Identifier: identifier, Operation: "delete workshop item", Err: err}
+	}
+
+	modConfig.Identifier = 0
+	err = appConfig.Save()
+	if err != nil {
+		return err
+	}
+
+	manifestPath := appConfig.ManifestFilePath()
+	manifest, err := loadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := manifest.Mods[identifier]; !ok {
+		return nil
+	}
+	delete(manifest.Mods, identifier)
+	return manifest.save(manifestPath)
+}
@@ -0,0 +1,76 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// dependenciesManaged reports if the required items of a mod are managed by the configuration
+// or the workshop relationships in the metadata file.
+// An empty configured list removes all required items, while no list leaves them untouched.
+func dependenciesManaged(data *ModUploadData) bool {
+	return data.Dependencies != nil
+}
+
+// syncDependencies adds and removes required items,
+// until the children of the workshop item match the configured dependencies.
+func syncDependencies(ctx context.Context, data *ModUploadData, current *ItemDetails) error {
+	for _, dependency := range data.Dependencies {
+		if slices.Contains(current.Children, dependency) {
+			continue
+		}
+		err := backend.AddDependency(ctx, data.Config.Identifier, dependency)
+		if err != nil {
+			return &UploadError{Identifier: data.Config.Identifier, Operation: fmt.Sprintf("add dependency %d", dependency), Err: err}
+		}
+	}
+
+	for _, child := range current.Children {
+		if slices.Contains(data.Dependencies, child) {
+			continue
+		}
+		err := backend.RemoveDependency(ctx, data.Config.Identifier, child)
+		if err != nil {
+			return &UploadError{Identifier: data.Config.Identifier, Operation: fmt.Sprintf("remove dependency %d", child), Err: err}
+		}
+	}
+
+	return nil
+}
+
+// syncAppDependencies adds and removes required DLCs and apps,
+// until the app dependencies of the workshop item match the configured ones.
+// No configured list leaves them untouched.
+func syncAppDependencies(ctx context.Context, data *ModUploadData) error {
+	if data.Config.AppDependencies == nil {
+		return nil
+	}
+
+	current, err := backend.GetAppDependencies(ctx, data.Config.Identifier)
+	if err != nil {
+		return &UploadError{Identifier: data.Config.Identifier, Operation: "query app dependencies", Err: err}
+	}
+
+	for _, app := range data.Config.AppDependencies {
+		if slices.Contains(current, app) {
+			continue
+		}
+		err := backend.AddAppDependency(ctx, data.Config.Identifier, app)
+		if err != nil {
+			return &UploadError{Identifier: data.Config.Identifier, Operation: fmt.Sprintf("add app dependency %d", app), Err: err}
+		}
+	}
+
+	for _, app := range current {
+		if slices.Contains(data.Config.AppDependencies, app) {
+			continue
+		}
+		err := backend.RemoveAppDependency(ctx, data.Config.Identifier, app)
+		if err != nil {
+			return &UploadError{Identifier: data.Config.Identifier, Operation: fmt.Sprintf("remove app dependency %d", app), Err: err}
+		}
+	}
+
+	return nil
+}
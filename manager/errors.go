@@ -0,0 +1,62 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+var (
+	// ErrNotPublished is returned for operations that require a mod with a workshop item.
+	ErrNotPublished = errors.New("mod is not published to the workshop")
+	// ErrItemNotFound is returned if the workshop item of a mod does not exist.
+	ErrItemNotFound = errors.New("workshop item not found")
+	// ErrWrongGame is returned if the workshop item of a mod belongs to another game.
+	ErrWrongGame = errors.New("workshop item belongs to another game")
+	// ErrNotInitialized is returned by the SteamBackend if the steam API was not initialized using Init.
+	ErrNotInitialized = errors.New("steam api is not initialized")
+	// ErrLegalAgreement is returned if the workshop legal agreement has not been accepted.
+	ErrLegalAgreement = errors.New("to make your item public you need to agree to the workshop terms of service <https://steamcommunity.com/sharedfiles/workshoplegalagreement>")
+)
+
+// InvalidModError is returned if a mod can not be uploaded because its files or configuration are invalid.
+// Nothing has been sent to steam yet.
+type InvalidModError struct {
+	Directory string
+	Err       error
+}
+
+func (err *InvalidModError) Error() string {
+	return fmt.Sprintf("invalid mod %s: %v", err.Directory, err.Err)
+}
+
+func (err *InvalidModError) Unwrap() error {
+	return err.Err
+}
+
+// UploadError is returned if a step of uploading a mod to the workshop or deleting it failed.
+type UploadError struct {
+	// Identifier of the workshop item, 0 if it could not be created
+	Identifier uint64
+	// Language of the failed update, empty if the step does not concern a single language
+	Language steam.ApiLanguage
+	// Operation describes the failed step, e.g. "add dependency 123"
+	Operation string
+	Err       error
+}
+
+func (err *UploadError) Error() string {
+	switch {
+	case err.Identifier == 0:
+		return fmt.Sprintf("failed to %s: %v", err.Operation, err.Err)
+	case err.Language == "":
+		return fmt.Sprintf("failed to %s of mod %d: %v", err.Operation, err.Identifier, err.Err)
+	default:
+		return fmt.Sprintf("failed to %s of mod %d for '%s': %v", err.Operation, err.Identifier, err.Language, err.Err)
+	}
+}
+
+func (err *UploadError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,235 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// FakeBackend is an in-memory workshop that records every submitted update.
+// It is used to run the upload pipeline without a steam client.
+type FakeBackend struct {
+	Items           map[uint64]*ItemDetails
+	AppDependencies map[uint64][]uint
+	Updates         []ItemUpdate
+	NextIdentifier  uint64
+	// CreateErrors are returned by the next CreateItem calls in order
+	CreateErrors []error
+	// SubmitErrors are returned by the next SubmitItemUpdate calls in order
+	SubmitErrors []error
+	// QueryErrors are returned by the next QueryItem calls in order
+	QueryErrors []error
+}
+
+func NewFakeBackend() *FakeBackend {
+	return &FakeBackend{
+		Items:           make(map[uint64]*ItemDetails),
+		AppDependencies: make(map[uint64][]uint),
+		Updates:         make([]ItemUpdate, 0),
+		NextIdentifier:  1,
+	}
+}
+
+func (b *FakeBackend) CreateItem(ctx context.Context, _ uint) (uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if err := nextError(&b.CreateErrors); err != nil {
+		return 0, err
+	}
+
+	identifier := b.NextIdentifier
+	b.NextIdentifier++
+	b.Items[identifier] = &ItemDetails{
+		Identifier: identifier,
+		Visibility: steam.Private,
+	}
+	return identifier, nil
+}
+
+func (b *FakeBackend) SubmitItemUpdate(ctx context.Context, update *ItemUpdate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := nextError(&b.SubmitErrors); err != nil {
+		return err
+	}
+
+	item, ok := b.Items[update.Identifier]
+	if !ok {
+		return steam.NewItemUpdateError(steam.K_EResultFileNotFound)
+	}
+
+	recorded := *update
+	recorded.Tags = slices.Clone(update.Tags)
+	recorded.KeyValueTags = maps.Clone(update.KeyValueTags)
+	recorded.UpdatePreviewFiles = maps.Clone(update.UpdatePreviewFiles)
+	recorded.RemovePreviews = slices.Clone(update.RemovePreviews)
+	recorded.AddPreviewFiles = slices.Clone(update.AddPreviewFiles)
+	recorded.AddPreviewVideos = slices.Clone(update.AddPreviewVideos)
+	b.Updates = append(b.Updates, recorded)
+
+	if update.Language == steam.English {
+		if update.Title != "" {
+			item.Title = update.Title
+		}
+		if update.Description != "" {
+			item.Description = update.Description
+		}
+	}
+	if len(update.Tags) > 0 {
+		item.Tags = slices.Clone(update.Tags)
+	}
+	if update.KeyValueTags != nil {
+		item.KeyValueTags = maps.Clone(update.KeyValueTags)
+	}
+	if update.Metadata != "" {
+		item.Metadata = update.Metadata
+	}
+	if update.MinGameBranch != "" || update.MaxGameBranch != "" {
+		item.MinGameBranch = update.MinGameBranch
+		item.MaxGameBranch = update.MaxGameBranch
+	}
+	if update.Visibility != "" {
+		item.Visibility = update.Visibility
+	}
+	applyPreviewChanges(item, update)
+	return nil
+}
+
+func (b *FakeBackend) QueryItem(ctx context.Context, _ uint, identifier uint64) (*ItemDetails, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := nextError(&b.QueryErrors); err != nil {
+		return nil, err
+	}
+
+	item, ok := b.Items[identifier]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrItemNotFound, identifier)
+	}
+
+	details := *item
+	details.Tags = slices.Clone(item.Tags)
+	details.KeyValueTags = maps.Clone(item.KeyValueTags)
+	details.Previews = slices.Clone(item.Previews)
+	details.Children = slices.Clone(item.Children)
+	return &details, nil
+}
+
+func (b *FakeBackend) AddDependency(_ context.Context, parent uint64, child uint64) error {
+	item, ok := b.Items[parent]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrItemNotFound, parent)
+	}
+	if !slices.Contains(item.Children, child) {
+		item.Children = append(item.Children, child)
+	}
+	return nil
+}
+
+func (b *FakeBackend) RemoveDependency(_ context.Context, parent uint64, child uint64) error {
+	item, ok := b.Items[parent]
+	if !ok {
+		return fmt.Errorf("%w: %d", ErrItemNotFound, parent)
+	}
+	item.Children = slices.DeleteFunc(item.Children, func(identifier uint64) bool {
+		return identifier == child
+	})
+	return nil
+}
+
+// UpdatesFor returns all recorded updates of a workshop item.
+func (b *FakeBackend) UpdatesFor(identifier uint64) []ItemUpdate {
+	var updates []ItemUpdate
+	for _, update := range b.Updates {
+		if update.Identifier == identifier {
+			updates = append(updates, update)
+		}
+	}
+	return updates
+}
+
+func (b *FakeBackend) GetAppDependencies(_ context.Context, identifier uint64) ([]uint, error) {
+	if _, ok := b.Items[identifier]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrItemNotFound, identifier)
+	}
+	return slices.Clone(b.AppDependencies[identifier]), nil
+}
+
+func (b *FakeBackend) AddAppDependency(_ context.Context, identifier uint64, app uint) error {
+	if _, ok := b.Items[identifier]; !ok {
+		return fmt.Errorf("%w: %d", ErrItemNotFound, identifier)
+	}
+	if !slices.Contains(b.AppDependencies[identifier], app) {
+		b.AppDependencies[identifier] = append(b.AppDependencies[identifier], app)
+	}
+	return nil
+}
+
+func (b *FakeBackend) RemoveAppDependency(_ context.Context, identifier uint64, app uint) error {
+	if _, ok := b.Items[identifier]; !ok {
+		return fmt.Errorf("%w: %d", ErrItemNotFound, identifier)
+	}
+	b.AppDependencies[identifier] = slices.DeleteFunc(b.AppDependencies[identifier], func(dependency uint) bool {
+		return dependency == app
+	})
+	return nil
+}
+
+func (b *FakeBackend) DeleteItem(_ context.Context, identifier uint64) error {
+	if _, ok := b.Items[identifier]; !ok {
+		return fmt.Errorf("%w: %d", ErrItemNotFound, identifier)
+	}
+	delete(b.Items, identifier)
+	delete(b.AppDependencies, identifier)
+	return nil
+}
+
+func applyPreviewChanges(item *ItemDetails, update *ItemUpdate) {
+	for index, path := range update.UpdatePreviewFiles {
+		if index < uint(len(item.Previews)) {
+			item.Previews[index].Source = path
+			item.Previews[index].FileName = filepath.Base(path)
+		}
+	}
+
+	previews := make([]ItemPreview, 0, len(item.Previews))
+	for _, preview := range item.Previews {
+		if !slices.Contains(update.RemovePreviews, preview.Index) {
+			previews = append(previews, preview)
+		}
+	}
+	for _, path := range update.AddPreviewFiles {
+		previews = append(previews, ItemPreview{
+			Type:     steam.K_EItemPreviewType_Image,
+			Source:   path,
+			FileName: filepath.Base(path),
+		})
+	}
+	for _, video := range update.AddPreviewVideos {
+		previews = append(previews, ItemPreview{
+			Type:   steam.K_EItemPreviewType_YouTubeVideo,
+			Source: video,
+		})
+	}
+
+	for index := range previews {
+		previews[index].Index = uint(index)
+	}
+	item.Previews = previews
+}
+
+func nextError(errs *[]error) error {
+	if len(*errs) == 0 {
+		return nil
+	}
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
@@ -0,0 +1,111 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+var galleryExtensions = []string{".png", ".jpg", ".jpeg", ".gif"}
+
+// galleryManaged reports if the additional previews of a mod are managed by the configuration.
+// If they are, previews that are not configured get removed from the workshop item.
+func galleryManaged(modConfig *config.ModConfig) bool {
+	return modConfig.Gallery != "" || len(modConfig.Videos) > 0
+}
+
+func readGallery(directory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gallery directory %s: %w", directory, err)
+	}
+
+	images := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if !slices.Contains(galleryExtensions, strings.ToLower(filepath.Ext(entry.Name()))) {
+			continue
+		}
+		image, err := filepath.Abs(filepath.Join(directory, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve absolute gallery image path: %w", err)
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// hashGallery returns the content hashes of the gallery images by their file name.
+func hashGallery(images []string) (map[string]string, error) {
+	if len(images) == 0 {
+		return nil, nil
+	}
+	hashes := make(map[string]string, len(images))
+	for _, image := range images {
+		digest := sha256.New()
+		err := hashFile(digest, image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash gallery image %s: %w", image, err)
+		}
+		hashes[filepath.Base(image)] = hex.EncodeToString(digest.Sum(nil))
+	}
+	return hashes, nil
+}
+
+// syncGallery adds the preview changes to the update
+// that turn the current previews of the item into the configured gallery.
+// Images are matched by their file name and videos by their YouTube id.
+// Matched images are only uploaded again if their hash differs from the previous upload.
+func syncGallery(data *ModUploadData, current *ItemDetails, update *ItemUpdate, hashes map[string]string, previous map[string]string) {
+	images := make(map[string]string, len(data.GalleryImages))
+	for _, image := range data.GalleryImages {
+		images[filepath.Base(image)] = image
+	}
+	videos := make(map[string]bool, len(data.Videos))
+	for _, video := range data.Videos {
+		videos[video] = true
+	}
+
+	update.UpdatePreviewFiles = make(map[uint]string)
+	for _, preview := range current.Previews {
+		switch preview.Type {
+		case steam.K_EItemPreviewType_Image:
+			image, ok := images[preview.FileName]
+			if !ok {
+				update.RemovePreviews = append(update.RemovePreviews, preview.Index)
+				continue
+			}
+			if hash, ok := previous[preview.FileName]; !ok || hash != hashes[preview.FileName] {
+				update.UpdatePreviewFiles[preview.Index] = image
+			}
+			delete(images, preview.FileName)
+		case steam.K_EItemPreviewType_YouTubeVideo:
+			if !videos[preview.Source] {
+				update.RemovePreviews = append(update.RemovePreviews, preview.Index)
+				continue
+			}
+			delete(videos, preview.Source)
+		}
+	}
+
+	for _, image := range data.GalleryImages {
+		if _, ok := images[filepath.Base(image)]; ok {
+			update.AddPreviewFiles = append(update.AddPreviewFiles, image)
+		}
+	}
+	for _, video := range data.Videos {
+		if videos[video] {
+			update.AddPreviewVideos = append(update.AddPreviewVideos, video)
+			delete(videos, video)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package manager
+
+import (
+	"fmt"
+	"strings"
+
+	"bahmut.de/pdx-workshop-manager/bbcode"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// LintIssue is a problem found in a title, description or change note of a mod.
+type LintIssue struct {
+	// Source is the file or field the issue was found in
+	Source string
+	bbcode.Issue
+}
+
+func (issue LintIssue) String() string {
+	if issue.Line == 0 {
+		return fmt.Sprintf("%s: %s", issue.Source, issue.Issue.String())
+	}
+	return fmt.Sprintf("%s:%s", issue.Source, issue.Issue.String())
+}
+
+// LintError is returned if the titles, descriptions or change notes of a mod would be rejected by steam.
+// It contains all issues, including warnings.
+type LintError struct {
+	Issues []LintIssue
+}
+
+func (err *LintError) Error() string {
+	var builder strings.Builder
+	builder.WriteString("invalid bbcode:")
+	for _, issue := range err.Issues {
+		if issue.Severity == bbcode.SeverityError {
+			builder.WriteString("\n  ")
+			builder.WriteString(issue.String())
+		}
+	}
+	return builder.String()
+}
+
+func lintText(source string, text string, maxLength int) []LintIssue {
+	var issues []LintIssue
+	for _, issue := range bbcode.Lint(text, maxLength) {
+		issues = append(issues, LintIssue{Source: source, Issue: issue})
+	}
+	return issues
+}
+
+func lintTitle(language steam.ApiLanguage, title string) []LintIssue {
+	if len(title) <= bbcode.MaxTitleLength {
+		return nil
+	}
+	return []LintIssue{{
+		Source: fmt.Sprintf("'%s' title", language),
+		Issue: bbcode.Issue{
+			Severity: bbcode.SeverityError,
+			Message:  fmt.Sprintf("title is %d bytes long, steam allows at most %d", len(title), bbcode.MaxTitleLength),
+		},
+	}}
+}
+
+// lintSource names the file issues were found in,
+// line and column of rendered templates and converted markdown files refer to the generated text.
+func lintSource(path string, rendered bool) string {
+	switch {
+	case rendered && isMarkdown(path):
+		return path + " (rendered as bbcode)"
+	case rendered:
+		return path + " (rendered)"
+	case isMarkdown(path):
+		return path + " (as bbcode)"
+	}
+	return path
+}
+
+// lintFailure returns a LintError if any of the issues is an error.
+func lintFailure(issues []LintIssue) error {
+	for _, issue := range issues {
+		if issue.Severity == bbcode.SeverityError {
+			return &LintError{Issues: issues}
+		}
+	}
+	return nil
+}
@@ -2,15 +2,20 @@ package manager
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
-	"time"
+	"strings"
+	"sync"
 
+	"bahmut.de/pdx-workshop-manager/bbcode"
 	"bahmut.de/pdx-workshop-manager/config"
 	"bahmut.de/pdx-workshop-manager/logging"
 	"bahmut.de/pdx-workshop-manager/steam"
@@ -22,17 +27,96 @@ type ModUploadData struct {
 	Descriptions map[steam.ApiLanguage]string
 	ChangeNotes  map[steam.ApiLanguage]string
 	Thumbnail    string
-	Metadata     *ModMetadata
-	Config       *config.ModConfig
+	// ConvertThumbnail is set if the thumbnail exceeds the steam limits and is converted before the upload
+	ConvertThumbnail bool
+	ContentPath      string
+	// ContentFiles are the slash separated paths of the uploaded files relative to the content path
+	ContentFiles  []string
+	GalleryImages []string
+	Videos        []string
+	Dependencies  []uint64
+	KeyValueTags  map[string]string
+	ItemMetadata  string
+	MinGameBranch string
+	MaxGameBranch string
+	// Visibility is sent with the item update, the configured visibility unless it is overridden
+	Visibility steam.Visibility
+	// Created is set if the item was created by this upload
+	Created bool
+	// LintIssues are the warnings found in the titles, descriptions and change notes
+	LintIssues []LintIssue
+	Metadata   *ModMetadata
+	Config     *config.ModConfig
 }
 
 type ModMetadata struct {
-	Name    string   `json:"name"`
-	Version string   `json:"version"`
-	Tags    []string `json:"tags"`
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	SupportedGameVersion string            `json:"supported_game_version"`
+	Tags                 []string          `json:"tags"`
+	Relationships        []ModRelationship `json:"relationships"`
 }
 
+// GameBranch returns the game branch matching the supported game version,
+// e.g. 1.9 for 1.9.* or 1.9.2 or an empty string if every game version is supported.
+func (metadata *ModMetadata) GameBranch() string {
+	version := strings.TrimSpace(metadata.SupportedGameVersion)
+	version = strings.TrimPrefix(version, "v")
+
+	// Game branches are named after the major and minor version
+	parts := strings.Split(version, ".")
+	parts = parts[:min(len(parts), 2)]
+	for len(parts) > 0 && (parts[len(parts)-1] == "*" || parts[len(parts)-1] == "") {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ".")
+}
+
+type ModRelationship struct {
+	Type         string `json:"rel_type"`
+	Identifier   string `json:"id"`
+	DisplayName  string `json:"display_name"`
+	ResourceType string `json:"resource_type"`
+	Version      string `json:"version"`
+}
+
+const RelationshipDependency = "dependency"
+
+// WorkshopDependency returns the workshop id of a required mod,
+// if the relationship references a steam workshop item.
+func (relationship ModRelationship) WorkshopDependency() (uint64, bool) {
+	if relationship.Type != RelationshipDependency {
+		return 0, false
+	}
+	if relationship.ResourceType != "" && relationship.ResourceType != "mod" {
+		return 0, false
+	}
+	identifier, err := strconv.ParseUint(strings.TrimSpace(relationship.Identifier), 10, 64)
+	if err != nil || identifier == 0 {
+		return 0, false
+	}
+	return identifier, true
+}
+
+var (
+	steamMutex sync.Mutex
+	// dispatcher runs all steam calls of the SteamBackend,
+	// it is started by the first Init and stopped by the last Shutdown
+	dispatcher *steam.Dispatcher
+	steamUsers int
+)
+
+// Init initializes the steam API for the game of the config.
+// Concurrent uploads share the steam API, only the first Init applies the config.
+// Every successful Init has to be followed by a Shutdown.
 func Init(appConfig *config.ApplicationConfig) error {
+	steamMutex.Lock()
+	defer steamMutex.Unlock()
+	if steamUsers > 0 {
+		steamUsers++
+		return nil
+	}
+
 	err := os.WriteFile(
 		"steam_appid.txt",
 		[]byte(strconv.FormatUint(uint64(appConfig.Game), 10)),
@@ -42,24 +126,94 @@ func Init(appConfig *config.ApplicationConfig) error {
 		return err
 	}
 
-	if !steam.SteamAPI_Init() {
-		return errors.New("failed to initialize steam api")
+	retryAttempts = DefaultRetryAttempts
+	if appConfig.RetryAttempts > 0 {
+		retryAttempts = appConfig.RetryAttempts
+	}
+
+	callTimeout, err = appConfig.ParseCallTimeout()
+	if err != nil {
+		return err
+	}
+	if callTimeout == 0 {
+		callTimeout = DefaultCallTimeout
 	}
+
+	dispatcher, err = steam.StartDispatcher(0)
+	if err != nil {
+		return err
+	}
+	steamUsers++
 	return nil
 }
 
-func UploadMod(appConfig *config.ApplicationConfig, modConfig *config.ModConfig) error {
-	data, err := createModUploadData(modConfig, appConfig.Game)
+// Shutdown stops dispatching steam callbacks and shuts the steam API down once the last user called it.
+func Shutdown() {
+	steamMutex.Lock()
+	defer steamMutex.Unlock()
+	if steamUsers == 0 {
+		return
+	}
+	steamUsers--
+	if steamUsers == 0 {
+		dispatcher.Stop()
+		dispatcher = nil
+	}
+}
+
+// steamDispatcher returns the dispatcher of the initialized steam API.
+func steamDispatcher() (*steam.Dispatcher, error) {
+	steamMutex.Lock()
+	defer steamMutex.Unlock()
+	if dispatcher == nil {
+		return nil, ErrNotInitialized
+	}
+	return dispatcher, nil
+}
+
+// UploadMode selects what UploadMod sends to steam.
+type UploadMode int
+
+const (
+	// UploadFull uploads the mod content and all workshop data
+	UploadFull UploadMode = iota
+	// UploadMetadataOnly updates titles, descriptions, tags, the thumbnail and the visibility
+	// without uploading content or adding a change note
+	UploadMetadataOnly
+)
+
+// UploadOptions are the settings of a single UploadMod call that are not part of the mod config.
+type UploadOptions struct {
+	Mode UploadMode
+	// Visibility replaces the configured visibility for this upload without changing the config
+	Visibility steam.Visibility
+}
+
+// UploadMod publishes a mod to the workshop and records what was uploaded in the manifest.
+// If the context is cancelled, the languages finished before are recorded as well.
+func UploadMod(ctx context.Context, appConfig *config.ApplicationConfig, modConfig *config.ModConfig, options UploadOptions) error {
+	data, err := prepareUpload(appConfig, modConfig, options)
 	if err != nil {
 		return err
 	}
+	for _, issue := range data.LintIssues {
+		logging.Warnf("%s", issue)
+	}
+
+	if options.Mode == UploadMetadataOnly {
+		if modConfig.Identifier == 0 {
+			return fmt.Errorf("metadata only updates require an already published mod: %w", ErrNotPublished)
+		}
+		return uploadModMetadataOnly(ctx, data)
+	}
 
-	if modConfig.Identifier == 0 {
-		identifier, err := createMod(appConfig.Game)
+	created := modConfig.Identifier == 0
+	if created {
+		identifier, err := createMod(ctx, appConfig.Game)
 		if err != nil {
 			return err
 		}
-		data.Config.Identifier = identifier
+		modConfig.Identifier = identifier
 	}
 
 	err = appConfig.Save()
@@ -67,14 +221,65 @@ func UploadMod(appConfig *config.ApplicationConfig, modConfig *config.ModConfig)
 		return err
 	}
 
-	err = uploadModData(data)
+	// Templates may use the workshop id, which is only known after creating the item
+	if created {
+		data, err = prepareUpload(appConfig, modConfig, options)
+		if err != nil {
+			return err
+		}
+		data.Created = true
+	}
+
+	manifestPath := appConfig.ManifestFilePath()
+	manifest, err := loadManifest(manifestPath)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	uploaded, uploadErr := uploadModData(ctx, data, manifest.Mods[data.Config.Identifier])
+	if uploaded == nil {
+		return uploadErr
+	}
+
+	manifest.Mods[data.Config.Identifier] = uploaded
+	err = manifest.save(manifestPath)
+	if uploadErr != nil {
+		logging.Warnf(
+			"Upload of mod %d stopped, finished languages: %s",
+			data.Config.Identifier,
+			slices.Sorted(maps.Keys(uploaded.Updates)),
+		)
+		if err != nil {
+			logging.Errorf("Failed to save upload manifest: %v", err)
+		}
+		return uploadErr
+	}
+	return err
 }
 
-func createModUploadData(config *config.ModConfig, game uint) (*ModUploadData, error) {
+// prepareUpload reads and validates the mod and applies the options of the upload.
+func prepareUpload(appConfig *config.ApplicationConfig, modConfig *config.ModConfig, options UploadOptions) (*ModUploadData, error) {
+	data, err := PrepareMod(appConfig, modConfig)
+	if err != nil {
+		return nil, err
+	}
+	if options.Visibility != "" {
+		data.Visibility = options.Visibility
+	}
+	return data, nil
+}
+
+// PrepareMod reads and validates everything that would be uploaded for a mod
+// without calling steam.
+func PrepareMod(appConfig *config.ApplicationConfig, modConfig *config.ModConfig) (*ModUploadData, error) {
+	data, err := createModUploadData(modConfig, appConfig.Game, appConfig.IncludeDirectory)
+	if err != nil {
+		return nil, &InvalidModError{Directory: modConfig.Directory, Err: err}
+	}
+	return data, nil
+}
+
+func createModUploadData(config *config.ModConfig, game uint, includeDirectory string) (*ModUploadData, error) {
 	uploadData := &ModUploadData{}
 	uploadData.Config = config
 	uploadData.Game = game
@@ -116,21 +321,72 @@ func createModUploadData(config *config.ModConfig, game uint) (*ModUploadData, e
 	}
 
 	uploadData.Metadata = &metadata
+	uploadData.Visibility = config.Visibility
+	renderer := newTemplateRenderer(&metadata, config.Identifier, config.Directory, includeDirectory)
+
+	uploadData.ContentPath, err = filepath.Abs(config.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute content path: %w", err)
+	}
+
+	ignoreRules, err := readIgnoreRules(uploadData.ContentPath, config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	uploadData.ContentFiles, err = listContent(uploadData.ContentPath, ignoreRules)
+	if err != nil {
+		return nil, err
+	}
+
 	uploadData.Thumbnail = filepath.Join(config.Directory, config.Thumbnail)
 	if _, err := os.Stat(uploadData.Thumbnail); errors.Is(err, os.ErrNotExist) {
 		return nil, fmt.Errorf("failed to find steam thumbnail in the mod root: %s", uploadData.Thumbnail)
 	}
+	uploadData.ConvertThumbnail, err = checkThumbnail(uploadData.Thumbnail, config.ConvertThumbnail)
+	if err != nil {
+		return nil, err
+	}
 
-	if config.Descriptions != nil && len(config.Descriptions) > 0 {
-		for language, descFile := range config.Descriptions {
-			content, err := os.ReadFile(descFile)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read '%s' description file %s: %w", language, descFile, err)
+	if config.Gallery != "" {
+		uploadData.GalleryImages, err = readGallery(config.Gallery)
+		if err != nil {
+			return nil, err
+		}
+	}
+	uploadData.Videos = config.Videos
+
+	dependencies := slices.Clone(config.Dependencies)
+	for _, relationship := range metadata.Relationships {
+		if dependency, ok := relationship.WorkshopDependency(); ok {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	if dependencies != nil {
+		uploadData.Dependencies = make([]uint64, 0, len(dependencies))
+		for _, dependency := range dependencies {
+			if dependency == 0 || (config.Identifier != 0 && dependency == config.Identifier) {
+				return nil, fmt.Errorf("invalid dependency %d: a mod can not depend on itself or an unpublished mod", dependency)
+			}
+			if !slices.Contains(uploadData.Dependencies, dependency) {
+				uploadData.Dependencies = append(uploadData.Dependencies, dependency)
 			}
-			uploadData.Descriptions[language] = string(content)
 		}
 	}
 
+	// Configured branches replace the ones derived from the metadata
+	if config.MinGameBranch != "" || config.MaxGameBranch != "" {
+		uploadData.MinGameBranch = config.MinGameBranch
+		uploadData.MaxGameBranch = config.MaxGameBranch
+	} else {
+		uploadData.MinGameBranch = metadata.GameBranch()
+		uploadData.MaxGameBranch = metadata.GameBranch()
+	}
+
+	err = stampMod(uploadData, config, renderer)
+	if err != nil {
+		return nil, err
+	}
+
 	uploadData.Names[steam.English] = metadata.Name
 	if config.Names != nil && len(config.Names) > 0 {
 		for language, name := range config.Names {
@@ -138,106 +394,289 @@ func createModUploadData(config *config.ModConfig, game uint) (*ModUploadData, e
 		}
 	}
 
+	var lintIssues []LintIssue
+	for language, name := range uploadData.Names {
+		lintIssues = append(lintIssues, lintTitle(language, name)...)
+	}
+
 	if config.Descriptions != nil && len(config.Descriptions) > 0 {
 		for language, descFile := range config.Descriptions {
-			content, err := os.ReadFile(descFile)
+			content, rendered, err := readBBCode(descFile, renderer, language)
 			if err != nil {
 				return nil, fmt.Errorf("failed to read '%s' description file %s: %w", language, descFile, err)
 			}
-			uploadData.Descriptions[language] = string(content)
+			uploadData.Descriptions[language] = content
+			lintIssues = append(lintIssues, lintText(lintSource(descFile, rendered), content, bbcode.MaxDescriptionLength)...)
 		}
 	}
 
 	if config.ChangeNoteDirectories != nil && len(config.ChangeNoteDirectories) > 0 {
 		for language, descFile := range config.ChangeNoteDirectories {
 			changeNotePath := filepath.Join(descFile, metadata.Version+".bbcode")
-			content, err := os.ReadFile(changeNotePath)
+			// Fall back to a markdown change note
+			if _, err := os.Stat(changeNotePath); errors.Is(err, os.ErrNotExist) {
+				markdownPath := filepath.Join(descFile, metadata.Version+bbcode.MarkdownExtension)
+				if _, err := os.Stat(markdownPath); err == nil {
+					changeNotePath = markdownPath
+				}
+			}
+			content, rendered, err := readBBCode(changeNotePath, renderer, language)
 			if err != nil {
 				logging.Warnf("failed to read '%s' changeNote file %s: %v", language, changeNotePath, err)
 			} else {
-				uploadData.ChangeNotes[language] = string(content)
+				uploadData.ChangeNotes[language] = content
+				lintIssues = append(lintIssues, lintText(lintSource(changeNotePath, rendered), content, bbcode.MaxChangeNoteLength)...)
 			}
 		}
 	}
 
+	for language, changelog := range config.Changelogs {
+		section, err := readChangelogSection(changelog, metadata.Version)
+		rendered := section
+		if err == nil {
+			rendered, err = renderer.render(changelog, section, language)
+		}
+		if err != nil {
+			logging.Warnf("failed to read '%s' changelog %s: %v", language, changelog, err)
+		} else {
+			changeNote := rendered
+			if isMarkdown(changelog) {
+				changeNote = bbcode.FromMarkdown(rendered)
+			}
+			uploadData.ChangeNotes[language] = changeNote
+			source := fmt.Sprintf("%s (section %s)", lintSource(changelog, rendered != section), metadata.Version)
+			lintIssues = append(lintIssues, lintText(source, changeNote, bbcode.MaxChangeNoteLength)...)
+		}
+	}
+
+	uploadData.LintIssues = lintIssues
+	err = lintFailure(lintIssues)
+	if err != nil {
+		return nil, err
+	}
+
 	return uploadData, nil
 }
 
-func createMod(game uint) (uint64, error) {
-	var steamError = false
+// readBBCode reads and renders a description or change note template,
+// converting it to bbcode if it is a markdown file.
+// It also reports if rendering the template changed the text.
+func readBBCode(path string, renderer *templateRenderer, language steam.ApiLanguage) (string, bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, err
+	}
+	rendered, err := renderer.render(path, string(content), language)
+	if err != nil {
+		return "", false, err
+	}
+	if isMarkdown(path) {
+		return bbcode.FromMarkdown(rendered), rendered != string(content), nil
+	}
+	return rendered, rendered != string(content), nil
+}
+
+func isMarkdown(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), bbcode.MarkdownExtension)
+}
 
-	apiCall := steam.SteamUGC().CreateItem(
-		game,
-		steam.K_EWorkshopFileTypeCommunity,
-	)
+func createMod(ctx context.Context, game uint) (uint64, error) {
+	identifier, err := retryIf(ctx, "creating the workshop item", isUnapplied, func() (uint64, error) {
+		return backend.CreateItem(ctx, game)
+	})
+	if err != nil {
+		return 0, &UploadError{Operation: "create workshop item", Err: err}
+	}
+	return identifier, nil
+}
 
-	result := steam.NewCreateItemResult_t()
-	for {
-		if steam.SteamUtils().IsAPICallCompleted(apiCall, &steamError) {
-			steam.SteamUtils().GetAPICallResult(
-				apiCall,
-				result.Swigcptr(),
-				steam.Sizeof_CreateItemResult_t,
-				steam.CreateItemResult_tK_iCallback,
-				&steamError,
-			)
-			break
+// uploadModData sends everything that changed since the previous upload recorded in the manifest
+// and returns the manifest of this upload.
+// If the upload fails after the item update, the manifest contains the languages finished before.
+func uploadModData(ctx context.Context, data *ModUploadData, previous *ModManifest) (*ModManifest, error) {
+	thumbnailPath, err := filepath.Abs(data.Thumbnail)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve absolute thumbnail path: %w", err)
+	}
+
+	uploaded := &ModManifest{Updates: make(map[steam.ApiLanguage]string)}
+	uploaded.Content, err = hashContent(data.ContentPath, data.ContentFiles)
+	if err != nil {
+		return nil, err
+	}
+	contentChanged := previous == nil || previous.Content != uploaded.Content
+	uploaded.Gallery, err = hashGallery(data.GalleryImages)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the files that are not ignored are uploaded from a staging copy of the mod
+	var stagingPath string
+	if contentChanged {
+		stagingPath, err = stageContent(data.ContentPath, data.ContentFiles)
+		if err != nil {
+			return nil, err
 		}
-		time.Sleep(500 * time.Millisecond)
+		defer func(stagingPath string) {
+			err := os.RemoveAll(stagingPath)
+			if err != nil {
+				logging.Warnf("failed to remove staging directory %s: %v", stagingPath, err)
+			}
+		}(stagingPath)
 	}
 
-	if result.GetM_bUserNeedsToAcceptWorkshopLegalAgreement() {
-		return 0, errors.New("to make your item public you need to agree to the workshop terms of service <https://steamcommunity.com/sharedfiles/workshoplegalagreement>")
+	update := &ItemUpdate{
+		Game:          data.Game,
+		Identifier:    data.Config.Identifier,
+		Language:      steam.English,
+		Title:         data.Metadata.Name,
+		ContentPath:   stagingPath,
+		PreviewPath:   thumbnailPath,
+		Tags:          data.Metadata.Tags,
+		KeyValueTags:  data.KeyValueTags,
+		Metadata:      data.ItemMetadata,
+		MinGameBranch: data.MinGameBranch,
+		MaxGameBranch: data.MaxGameBranch,
+		Visibility:    data.Visibility,
+		ChangeNote:    data.ChangeNotes[steam.English],
 	}
 
-	if result.GetM_eResult() != steam.K_EResultOK {
-		return 0, fmt.Errorf("steam API call failed: %s", steam.ResultDescription[result.GetM_eResult()])
+	if data.Names != nil && data.Names[steam.English] != "" {
+		update.Title = data.Names[steam.English]
 	}
 
-	if steamError {
-		return 0, fmt.Errorf("steam API call failed: %v", steam.SteamUtils().GetAPICallFailureReason(apiCall))
+	if data.Descriptions != nil && data.Descriptions[steam.English] != "" {
+		update.Description = data.Descriptions[steam.English]
 	}
 
-	return result.GetM_nPublishedFileId(), nil
-}
+	// Unchanged content is neither uploaded again nor announced with a change note
+	if !contentChanged {
+		update.ChangeNote = ""
+	}
+
+	// Stamps like {{timestamp}} change on every upload, so their templates are compared instead
+	// and the rendered stamps are only sent along with other changes
+	fingerprinted := *update
+	fingerprinted.KeyValueTags = data.Config.KeyValueTags
+	fingerprinted.Metadata = data.Config.Metadata
+	uploaded.Updates[steam.English], err = fingerprintUpdate(&fingerprinted, data.Videos, append([]string{thumbnailPath}, data.GalleryImages...))
+	if err != nil {
+		return nil, err
+	}
 
-func uploadModData(data *ModUploadData) error {
-	handle := steam.SteamUGC().StartItemUpdate(data.Game, data.Config.Identifier)
+	// New items stay hidden until they are promoted, later uploads keep the visibility on steam
+	if data.Created && update.Visibility == "" {
+		update.Visibility = steam.Private
+	}
+	updateChanged := contentChanged || previous.Updates[steam.English] != uploaded.Updates[steam.English]
 
-	if data.Names != nil && data.Names[steam.English] != "" {
-		steam.SteamUGC().SetItemTitle(handle, data.Names[steam.English])
+	var current *ItemDetails
+	if (updateChanged && galleryManaged(data.Config)) || dependenciesManaged(data) {
+		current, err = backend.QueryItem(ctx, data.Game, data.Config.Identifier)
+		if err != nil {
+			return nil, &UploadError{Identifier: data.Config.Identifier, Operation: "query workshop item", Err: err}
+		}
+	}
+
+	if updateChanged {
+		previewPath, cleanup, err := previewThumbnail(data, thumbnailPath)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		update.PreviewPath = previewPath
+
+		if galleryManaged(data.Config) {
+			var previousGallery map[string]string
+			if previous != nil {
+				previousGallery = previous.Gallery
+			}
+			syncGallery(data, current, update, uploaded.Gallery, previousGallery)
+		}
+
+		err = uploadUpdate(ctx, update)
+		if err != nil {
+			return nil, err
+		}
 	} else {
-		steam.SteamUGC().SetItemTitle(handle, data.Metadata.Name)
+		logging.Infof("Mod %d is unchanged since the last upload, skipping item update", data.Config.Identifier)
 	}
 
-	if data.Descriptions != nil && data.Descriptions[steam.English] != "" {
-		steam.SteamUGC().SetItemDescription(handle, data.Descriptions[steam.English])
+	if dependenciesManaged(data) {
+		err = syncDependencies(ctx, data, current)
+		if err != nil {
+			return uploaded, err
+		}
 	}
 
-	contentPath, err := filepath.Abs(data.Config.Directory)
+	err = syncAppDependencies(ctx, data)
 	if err != nil {
-		return fmt.Errorf("failed to resolve absolute content path: %w", err)
+		return uploaded, err
 	}
-	steam.SteamUGC().SetItemContent(handle, contentPath)
 
+	// Languages keep their change note until their update succeeded, even if a later upload has unchanged content
+	if contentChanged {
+		uploaded.PendingChangeNotes = localizedLanguages(data)
+	} else {
+		uploaded.PendingChangeNotes = slices.Clone(previous.PendingChangeNotes)
+	}
+	for _, language := range localizedLanguages(data) {
+		sendChangeNote := slices.Contains(uploaded.PendingChangeNotes, language)
+		fingerprint, err := uploadModMetadata(ctx, data, language, sendChangeNote, previous)
+		if err != nil {
+			return uploaded, err
+		}
+		uploaded.Updates[language] = fingerprint
+		uploaded.PendingChangeNotes = slices.DeleteFunc(uploaded.PendingChangeNotes, func(pending steam.ApiLanguage) bool {
+			return pending == language
+		})
+	}
+
+	return uploaded, nil
+}
+
+// uploadModMetadataOnly sends the names, descriptions, tags, thumbnail and visibility of a mod
+// without touching its content.
+func uploadModMetadataOnly(ctx context.Context, data *ModUploadData) error {
 	thumbnailPath, err := filepath.Abs(data.Thumbnail)
 	if err != nil {
 		return fmt.Errorf("failed to resolve absolute thumbnail path: %w", err)
 	}
-	steam.SteamUGC().SetItemPreview(handle, thumbnailPath)
 
-	if len(data.Metadata.Tags) > 0 {
-		tagArray := steam.NewSteamParamStringArray(data.Metadata.Tags)
-		steam.SteamUGC().SetItemTagsExtension(handle, tagArray)
+	previewPath, cleanup, err := previewThumbnail(data, thumbnailPath)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
 
-	steam.SteamUGC().SetItemUpdateLanguage(handle, steam.English.GetString())
+	update := localizedUpdate(data, steam.English)
+	update.ChangeNote = ""
+	update.PreviewPath = previewPath
+	update.Tags = data.Metadata.Tags
+	update.Visibility = data.Visibility
+	if update.Title == "" {
+		update.Title = data.Metadata.Name
+	}
 
-	err = uploadUpdate(handle, data.ChangeNotes[steam.English])
+	err = uploadUpdate(ctx, update)
 	if err != nil {
 		return err
 	}
 
+	for _, language := range localizedLanguages(data) {
+		update := localizedUpdate(data, language)
+		update.ChangeNote = ""
+		err = uploadUpdate(ctx, update)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localizedLanguages returns all languages besides english with a name or description.
+func localizedLanguages(data *ModUploadData) []steam.ApiLanguage {
 	languages := map[steam.ApiLanguage]bool{}
 	for key := range data.Names {
 		languages[key] = true
@@ -245,66 +684,62 @@ func uploadModData(data *ModUploadData) error {
 	for key := range data.Descriptions {
 		languages[key] = true
 	}
-
-	for language := range languages {
-		if language == steam.English {
-			continue
-		}
-		err = uploadModMetadata(data, language)
-		if err != nil {
-			return fmt.Errorf("failed to update '%s' name or description: %w", language, err)
-		}
-	}
-
-	return nil
+	delete(languages, steam.English)
+	return slices.Sorted(maps.Keys(languages))
 }
 
-func uploadModMetadata(data *ModUploadData, language steam.ApiLanguage) error {
-	handle := steam.SteamUGC().StartItemUpdate(data.Game, data.Config.Identifier)
+func localizedUpdate(data *ModUploadData, language steam.ApiLanguage) *ItemUpdate {
+	update := &ItemUpdate{
+		Game:       data.Game,
+		Identifier: data.Config.Identifier,
+		Language:   language,
+		ChangeNote: data.ChangeNotes[language],
+	}
 
 	if data.Names != nil && data.Names[language] != "" {
-		steam.SteamUGC().SetItemTitle(handle, data.Names[language])
+		update.Title = data.Names[language]
 	}
 
 	if data.Descriptions != nil && data.Descriptions[language] != "" {
-		steam.SteamUGC().SetItemDescription(handle, data.Descriptions[language])
+		update.Description = data.Descriptions[language]
 	}
 
-	steam.SteamUGC().SetItemUpdateLanguage(handle, language.GetString())
-
-	return uploadUpdate(handle, data.ChangeNotes[language])
+	return update
 }
 
-func uploadUpdate(handle uint64, changeNote string) error {
-	var steamError = false
-	result := steam.NewSubmitItemUpdateResult_t()
-	apiCall := steam.SteamUGC().SubmitItemUpdate(handle, changeNote)
-
-	for {
-		if steam.SteamUtils().IsAPICallCompleted(apiCall, &steamError) {
-			steam.SteamUtils().GetAPICallResult(
-				apiCall,
-				result.Swigcptr(),
-				steam.Sizeof_SubmitItemUpdateResult_t,
-				steam.SubmitItemUpdateResult_tK_iCallback,
-				&steamError,
-			)
-			break
-		}
-		time.Sleep(500 * time.Millisecond)
+// uploadModMetadata sends the localized name, description and change note of a language,
+// if they changed since the previous upload, and returns the fingerprint of the update.
+// The change note is only sent along if the content of the language was not announced yet.
+func uploadModMetadata(ctx context.Context, data *ModUploadData, language steam.ApiLanguage, sendChangeNote bool, previous *ModManifest) (string, error) {
+	update := localizedUpdate(data, language)
+	if !sendChangeNote {
+		update.ChangeNote = ""
 	}
 
-	if result.GetM_eResult() != steam.K_EResultOK {
-		errorMessage := steam.UgcItemUpdateDescription[result.GetM_eResult()]
-		if errorMessage == "" {
-			errorMessage = steam.ResultDescription[result.GetM_eResult()]
-		}
-		return fmt.Errorf("steam API call failed: %s", errorMessage)
+	fingerprint, err := fingerprintUpdate(update, nil, nil)
+	if err != nil {
+		return "", err
 	}
+	if !sendChangeNote && previous.Updates[language] == fingerprint {
+		return fingerprint, nil
+	}
+
+	return fingerprint, uploadUpdate(ctx, update)
+}
 
-	if steamError {
-		return fmt.Errorf("steam API call failed: %v", steam.SteamUtils().GetAPICallFailureReason(apiCall))
+func uploadUpdate(ctx context.Context, update *ItemUpdate) error {
+	// Every applied update adds its change note to the item history,
+	// so updates with a change note are only repeated if steam did not apply them
+	retryable := isRetryable
+	if update.ChangeNote != "" {
+		retryable = isUnapplied
 	}
 
+	_, err := retryIf(ctx, fmt.Sprintf("uploading '%s' update of mod %d", update.Language, update.Identifier), retryable, func() (struct{}, error) {
+		return struct{}{}, backend.SubmitItemUpdate(ctx, update)
+	})
+	if err != nil {
+		return &UploadError{Identifier: update.Identifier, Language: update.Language, Operation: "submit update", Err: err}
+	}
 	return nil
 }
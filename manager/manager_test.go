@@ -0,0 +1,488 @@
+package manager
+
+import (
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// newTestMod creates a mod with an english and german description and an english change note
+// and a config file in a temporary directory.
+func newTestMod(t *testing.T) (*config.ApplicationConfig, *config.ModConfig) {
+	t.Helper()
+	directory := t.TempDir()
+	modDirectory := filepath.Join(directory, "mod")
+
+	writeTestFile(t, filepath.Join(modDirectory, ".metadata", "metadata.json"),
+		`{"name": "Test Mod", "version": "1.0.0", "supported_game_version": "1.9.*", "tags": ["Gameplay"]}`)
+	writeTestFile(t, filepath.Join(modDirectory, "common", "test.txt"), "content")
+	writeTestFile(t, filepath.Join(directory, "description.bbcode"), "[b]{{.Name}}[/b] {{.Version}} {{.Identifier}}")
+	writeTestFile(t, filepath.Join(directory, "description_german.bbcode"), "Beschreibung")
+	writeTestFile(t, filepath.Join(directory, "changes", "1.0.0.bbcode"), "First release")
+
+	writeTestImage(t, filepath.Join(modDirectory, "thumbnail.png"), 16)
+
+	appConfig, err := config.InitializeConfig(filepath.Join(directory, config.DefaultFileName), 529340)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modConfig := &config.ModConfig{
+		Directory: modDirectory,
+		Thumbnail: "thumbnail.png",
+		Names: map[steam.ApiLanguage]string{
+			steam.German: "Testmod",
+		},
+		Descriptions: map[steam.ApiLanguage]string{
+			steam.English: filepath.Join(directory, "description.bbcode"),
+			steam.German:  filepath.Join(directory, "description_german.bbcode"),
+		},
+		ChangeNoteDirectories: map[steam.ApiLanguage]string{
+			steam.English: filepath.Join(directory, "changes"),
+		},
+	}
+	appConfig.Mods = append(appConfig.Mods, modConfig)
+	return appConfig, modConfig
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err == nil {
+		err = os.WriteFile(path, []byte(content), 0644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestImage(t *testing.T, path string, size int) {
+	t.Helper()
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = png.Encode(file, image.NewRGBA(image.Rect(0, 0, size, size)))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func useFakeBackend(t *testing.T) *FakeBackend {
+	t.Helper()
+	fake := NewFakeBackend()
+	SetBackend(fake)
+	t.Cleanup(func() {
+		SetBackend(&SteamBackend{})
+	})
+	return fake
+}
+
+func TestUploadModCreatesItem(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("UploadMod() error = %v", err)
+	}
+
+	if modConfig.Identifier != 1 {
+		t.Fatalf("Identifier = %d, want the created item 1", modConfig.Identifier)
+	}
+	item, ok := fake.Items[modConfig.Identifier]
+	if !ok {
+		t.Fatalf("item %d was not created", modConfig.Identifier)
+	}
+	if item.Title != "Test Mod" {
+		t.Errorf("Title = %q, want %q", item.Title, "Test Mod")
+	}
+	if item.Description != "[b]Test Mod[/b] 1.0.0 1" {
+		t.Errorf("Description = %q, want the description rendered with the created id", item.Description)
+	}
+	if item.Visibility != steam.Private || fake.Updates[0].Visibility != steam.Private {
+		t.Errorf("Visibility = %q, want new items to be %q", item.Visibility, steam.Private)
+	}
+
+	saved, err := config.LoadConfig(filepath.Join(filepath.Dir(modConfig.Directory), config.DefaultFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Mods[0].Identifier != modConfig.Identifier {
+		t.Errorf("saved Identifier = %d, want %d", saved.Mods[0].Identifier, modConfig.Identifier)
+	}
+}
+
+func TestUploadModSendsLocalizedUpdatesAfterEnglish(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("UploadMod() error = %v", err)
+	}
+
+	updates := fake.UpdatesFor(modConfig.Identifier)
+	if len(updates) != 2 {
+		t.Fatalf("got %d updates, want 2", len(updates))
+	}
+
+	english := updates[0]
+	if english.Language != steam.English {
+		t.Errorf("first update Language = %q, want %q", english.Language, steam.English)
+	}
+	if english.ContentPath == "" || english.PreviewPath == "" {
+		t.Errorf("english update has no content or preview: %+v", english)
+	}
+	if english.ChangeNote != "First release" {
+		t.Errorf("english ChangeNote = %q, want %q", english.ChangeNote, "First release")
+	}
+
+	german := updates[1]
+	if german.Language != steam.German {
+		t.Errorf("second update Language = %q, want %q", german.Language, steam.German)
+	}
+	if german.Title != "Testmod" || german.Description != "Beschreibung" {
+		t.Errorf("german update = %q / %q, want the localized title and description", german.Title, german.Description)
+	}
+	if german.ContentPath != "" || german.PreviewPath != "" {
+		t.Errorf("german update uploads content or preview: %+v", german)
+	}
+}
+
+func TestUploadModReturnsUploadError(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+	fake.SubmitErrors = []error{steam.NewItemUpdateError(steam.K_EResultAccessDenied)}
+
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+
+	var uploadError *UploadError
+	if !errors.As(err, &uploadError) {
+		t.Fatalf("UploadMod() error = %v, want an *UploadError", err)
+	}
+	if uploadError.Identifier != modConfig.Identifier || uploadError.Language != steam.English {
+		t.Errorf("UploadError = %+v, want the english update of mod %d", uploadError, modConfig.Identifier)
+	}
+	var resultError *steam.ResultError
+	if !errors.As(err, &resultError) || resultError.Result != steam.K_EResultAccessDenied {
+		t.Errorf("UploadMod() error = %v, want the steam result", err)
+	}
+	if len(fake.Updates) != 0 {
+		t.Errorf("got %d updates after the failed update, want none", len(fake.Updates))
+	}
+}
+
+func TestUploadModSkipsUnchangedMod(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("first UploadMod() error = %v", err)
+	}
+	uploaded := len(fake.Updates)
+
+	err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("second UploadMod() error = %v", err)
+	}
+	if len(fake.Updates) != uploaded {
+		t.Errorf("unchanged mod sent %d more updates, want none", len(fake.Updates)-uploaded)
+	}
+
+	writeTestFile(t, filepath.Join(modConfig.Directory, "common", "test.txt"), "changed content")
+	err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("third UploadMod() error = %v", err)
+	}
+	if len(fake.Updates) == uploaded {
+		t.Error("changed content was not uploaded")
+	}
+}
+
+func TestUploadModUpdatesChangedGalleryImages(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+	modConfig.Gallery = filepath.Join(filepath.Dir(modConfig.Directory), "gallery")
+	writeTestImage(t, filepath.Join(modConfig.Gallery, "a.png"), 8)
+	writeTestImage(t, filepath.Join(modConfig.Gallery, "b.png"), 8)
+
+	upload := func() ItemUpdate {
+		t.Helper()
+		err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+		if err != nil {
+			t.Fatalf("UploadMod() error = %v", err)
+		}
+		var english ItemUpdate
+		for _, update := range fake.UpdatesFor(modConfig.Identifier) {
+			if update.Language == steam.English {
+				english = update
+			}
+		}
+		return english
+	}
+
+	if update := upload(); len(update.AddPreviewFiles) != 2 {
+		t.Fatalf("AddPreviewFiles = %v, want both gallery images", update.AddPreviewFiles)
+	}
+
+	writeTestImage(t, filepath.Join(modConfig.Gallery, "b.png"), 12)
+	update := upload()
+	if len(update.UpdatePreviewFiles) != 1 || filepath.Base(update.UpdatePreviewFiles[1]) != "b.png" {
+		t.Errorf("UpdatePreviewFiles = %v, want only the changed image b.png", update.UpdatePreviewFiles)
+	}
+
+	writeTestFile(t, modConfig.Descriptions[steam.English], "Changed description")
+	update = upload()
+	if update.Description != "Changed description" || len(update.UpdatePreviewFiles) != 0 {
+		t.Errorf("UpdatePreviewFiles = %v, want no unchanged images to be uploaded again", update.UpdatePreviewFiles)
+	}
+}
+
+func TestUploadModKeepsPromotedVisibility(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("UploadMod() error = %v", err)
+	}
+	if modConfig.Visibility != "" {
+		t.Errorf("configured Visibility = %q, want creating the item to not change it", modConfig.Visibility)
+	}
+
+	// The item is made public on the workshop website
+	fake.Items[modConfig.Identifier].Visibility = steam.Public
+	uploaded := len(fake.Updates)
+
+	writeTestFile(t, filepath.Join(modConfig.Directory, "common", "test.txt"), "changed content")
+	err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("second UploadMod() error = %v", err)
+	}
+	if len(fake.Updates) == uploaded {
+		t.Fatal("changed content was not uploaded")
+	}
+	if update := fake.Updates[uploaded]; update.Visibility != "" {
+		t.Errorf("update Visibility = %q, want the visibility on steam to be kept", update.Visibility)
+	}
+	if visibility := fake.Items[modConfig.Identifier].Visibility; visibility != steam.Public {
+		t.Errorf("item Visibility = %q, want the promoted %q", visibility, steam.Public)
+	}
+}
+
+func TestUploadModMetadataOnlyAppliesVisibility(t *testing.T) {
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("UploadMod() error = %v", err)
+	}
+	uploaded := len(fake.Updates)
+
+	err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadMetadataOnly, Visibility: steam.Public})
+	if err != nil {
+		t.Fatalf("metadata only UploadMod() error = %v", err)
+	}
+
+	english := fake.Updates[uploaded]
+	if english.Language != steam.English || english.Visibility != steam.Public {
+		t.Errorf("update = %q with visibility %q, want english with %q", english.Language, english.Visibility, steam.Public)
+	}
+	if english.ContentPath != "" || english.ChangeNote != "" {
+		t.Errorf("metadata only update uploads content or a change note: %+v", english)
+	}
+	if modConfig.Visibility != "" {
+		t.Errorf("configured Visibility = %q, want the override to not change it", modConfig.Visibility)
+	}
+}
+
+func TestPrepareModReportsRenderedLintSource(t *testing.T) {
+	tests := []struct {
+		description string
+		wantSource  string
+	}{
+		{description: "[b]bold", wantSource: "description.bbcode"},
+		{description: "{{.Name}}\n[b]bold", wantSource: "description.bbcode (rendered)"},
+	}
+	for _, test := range tests {
+		appConfig, modConfig := newTestMod(t)
+		path := modConfig.Descriptions[steam.English]
+		writeTestFile(t, path, test.description)
+
+		_, err := PrepareMod(appConfig, modConfig)
+		var lintError *LintError
+		if !errors.As(err, &lintError) {
+			t.Fatalf("PrepareMod() error = %v, want a *LintError", err)
+		}
+		source := filepath.Join(filepath.Dir(path), test.wantSource)
+		if lintError.Issues[0].Source != source {
+			t.Errorf("Source = %q, want %q", lintError.Issues[0].Source, source)
+		}
+	}
+}
+
+func TestCreateModRetriesOnlyWhenNoItemWasCreated(t *testing.T) {
+	attempts, delay := retryAttempts, retryDelay
+	retryAttempts, retryDelay = 2, 0
+	t.Cleanup(func() {
+		retryAttempts, retryDelay = attempts, delay
+	})
+
+	tests := []struct {
+		result    steam.EResult
+		wantCalls int
+	}{
+		{result: steam.K_EResultBusy, wantCalls: 2},
+		{result: steam.K_EResultRateLimitExceeded, wantCalls: 2},
+		{result: steam.K_EResultServiceUnavailable, wantCalls: 2},
+		{result: steam.K_EResultTimeout, wantCalls: 1},
+		{result: steam.K_EResultRemoteDisconnect, wantCalls: 1},
+	}
+	for _, test := range tests {
+		fake := useFakeBackend(t)
+		fake.CreateErrors = []error{steam.NewResultError(test.result), steam.NewResultError(test.result)}
+
+		_, err := createMod(t.Context(), 529340)
+		if err == nil {
+			t.Fatalf("createMod() after %v error = nil, want the second failure", test.result)
+		}
+		if calls := 2 - len(fake.CreateErrors); calls != test.wantCalls {
+			t.Errorf("CreateItem was called %d times after %v, want %d", calls, test.result, test.wantCalls)
+		}
+	}
+}
+
+func TestGameBranch(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{version: "1.9.*", want: "1.9"},
+		{version: "1.9.2", want: "1.9"},
+		{version: "v1.9.2", want: "1.9"},
+		{version: "1.9", want: "1.9"},
+		{version: "1.*", want: "1"},
+		{version: "1.*.*", want: "1"},
+		{version: "*", want: ""},
+		{version: "", want: ""},
+	}
+	for _, test := range tests {
+		metadata := &ModMetadata{SupportedGameVersion: test.version}
+		if got := metadata.GameBranch(); got != test.want {
+			t.Errorf("GameBranch() of %q = %q, want %q", test.version, got, test.want)
+		}
+	}
+}
+
+func TestUploadModKeepsLocalizedChangeNoteOnRetry(t *testing.T) {
+	attempts, delay := retryAttempts, retryDelay
+	retryAttempts, retryDelay = 2, 0
+	t.Cleanup(func() {
+		retryAttempts, retryDelay = attempts, delay
+	})
+
+	tests := []struct {
+		name string
+		// errs are returned by the english and the german updates of the first upload
+		errs []error
+	}{
+		{name: "retried update", errs: []error{nil, steam.NewResultError(steam.K_EResultBusy)}},
+		{name: "next upload", errs: []error{nil, steam.NewItemUpdateError(steam.K_EResultAccessDenied)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := useFakeBackend(t)
+			appConfig, modConfig := newTestMod(t)
+			changes := filepath.Join(filepath.Dir(modConfig.Directory), "changes_german")
+			writeTestFile(t, filepath.Join(changes, "1.0.0.bbcode"), "Erste Version")
+			modConfig.ChangeNoteDirectories[steam.German] = changes
+			fake.SubmitErrors = test.errs
+
+			err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+			if err != nil {
+				err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+			}
+			if err != nil {
+				t.Fatalf("UploadMod() error = %v", err)
+			}
+
+			var german []ItemUpdate
+			for _, update := range fake.UpdatesFor(modConfig.Identifier) {
+				if update.Language == steam.German {
+					german = append(german, update)
+				}
+			}
+			if len(german) != 1 || german[0].ChangeNote != "Erste Version" {
+				t.Errorf("german updates = %+v, want one update with the change note", german)
+			}
+
+			// Once the change note was sent, unchanged content does not send it again
+			uploaded := len(fake.Updates)
+			err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+			if err != nil {
+				t.Fatalf("UploadMod() error = %v", err)
+			}
+			if len(fake.Updates) != uploaded {
+				t.Errorf("unchanged mod sent %d more updates, want none", len(fake.Updates)-uploaded)
+			}
+		})
+	}
+}
+
+func TestUploadModRetriesChangeNotesOnlyWhenNotApplied(t *testing.T) {
+	attempts, delay := retryAttempts, retryDelay
+	retryAttempts, retryDelay = 2, 0
+	t.Cleanup(func() {
+		retryAttempts, retryDelay = attempts, delay
+	})
+
+	tests := []struct {
+		result      steam.EResult
+		wantUpdates int
+	}{
+		{result: steam.K_EResultBusy, wantUpdates: 2},
+		{result: steam.K_EResultTimeout, wantUpdates: 0},
+		{result: steam.K_EResultNoConnection, wantUpdates: 0},
+	}
+	for _, test := range tests {
+		fake := useFakeBackend(t)
+		appConfig, modConfig := newTestMod(t)
+		fake.SubmitErrors = []error{steam.NewResultError(test.result)}
+
+		err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+		if (err == nil) != (test.wantUpdates > 0) {
+			t.Errorf("UploadMod() after %v error = %v", test.result, err)
+		}
+		if len(fake.Updates) != test.wantUpdates {
+			t.Errorf("got %d updates after %v, want %d", len(fake.Updates), test.result, test.wantUpdates)
+		}
+	}
+
+	// Updates without a change note can be repeated after any temporary failure
+	fake := useFakeBackend(t)
+	appConfig, modConfig := newTestMod(t)
+	err := UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadFull})
+	if err != nil {
+		t.Fatalf("UploadMod() error = %v", err)
+	}
+	fake.SubmitErrors = []error{steam.NewResultError(steam.K_EResultTimeout)}
+	err = UploadMod(t.Context(), appConfig, modConfig, UploadOptions{Mode: UploadMetadataOnly})
+	if err != nil {
+		t.Errorf("metadata only UploadMod() after a timeout error = %v, want the update to be retried", err)
+	}
+}
@@ -0,0 +1,126 @@
+package manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// UploadManifest records what was sent to steam by the last successful upload of each mod.
+type UploadManifest struct {
+	Mods map[uint64]*ModManifest `json:"mods"`
+}
+
+// ModManifest contains the content hash and the hashes of all item updates of a mod.
+type ModManifest struct {
+	Content string                       `json:"content"`
+	Updates map[steam.ApiLanguage]string `json:"updates"`
+	// Gallery contains the hashes of the uploaded gallery images by their file name
+	Gallery map[string]string `json:"gallery,omitempty"`
+	// PendingChangeNotes are the languages that did not get the change note of the content yet,
+	// because the upload stopped before their update
+	PendingChangeNotes []steam.ApiLanguage `json:"pending-change-notes,omitempty"`
+}
+
+func loadManifest(path string) (*UploadManifest, error) {
+	manifest := &UploadManifest{Mods: make(map[uint64]*ModManifest)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload manifest: %w", err)
+	}
+
+	err = json.Unmarshal(content, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse upload manifest: %w", err)
+	}
+	if manifest.Mods == nil {
+		manifest.Mods = make(map[uint64]*ModManifest)
+	}
+	return manifest, nil
+}
+
+func (manifest *UploadManifest) save(path string) error {
+	content, err := json.MarshalIndent(manifest, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to serialize upload manifest: %w", err)
+	}
+
+	err = os.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write upload manifest: %w", err)
+	}
+	return nil
+}
+
+// hashContent hashes the relative path and content of the given files in the directory.
+func hashContent(directory string, files []string) (string, error) {
+	digest := sha256.New()
+	for _, file := range files {
+		_, _ = io.WriteString(digest, file+"\x00")
+		err := hashFile(digest, filepath.Join(directory, filepath.FromSlash(file)))
+		if err != nil {
+			return "", fmt.Errorf("failed to hash mod content %s: %w", file, err)
+		}
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+func hashFile(digest hash.Hash, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	size, err := io.Copy(digest, file)
+	if err != nil {
+		return err
+	}
+	_, _ = io.WriteString(digest, "\x00"+strconv.FormatInt(size, 10)+"\x00")
+	return nil
+}
+
+// fingerprintUpdate hashes everything of an item update except the mod content and change note,
+// including the content of the referenced preview files.
+func fingerprintUpdate(update *ItemUpdate, videos []string, files []string) (string, error) {
+	fingerprinted := *update
+	fingerprinted.ContentPath = ""
+	fingerprinted.ChangeNote = ""
+
+	content, err := json.Marshal(struct {
+		Update *ItemUpdate
+		Videos []string
+	}{&fingerprinted, videos})
+	if err != nil {
+		return "", fmt.Errorf("failed to fingerprint item update: %w", err)
+	}
+
+	digest := sha256.New()
+	digest.Write(content)
+	for _, path := range files {
+		if path == "" {
+			continue
+		}
+		_, _ = io.WriteString(digest, path+"\x00")
+		err := hashFile(digest, path)
+		if err != nil {
+			return "", fmt.Errorf("failed to fingerprint file %s: %w", path, err)
+		}
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// UploadProgress is a snapshot of a running SubmitItemUpdate call
+// as reported by steam.
+type UploadProgress struct {
+	Identifier     uint64
+	Language       steam.ApiLanguage
+	Status         steam.EItemUpdateStatus
+	BytesProcessed uint64
+	BytesTotal     uint64
+	Finished       bool
+}
+
+type ProgressHandler func(progress UploadProgress)
+
+var progressHandlers []ProgressHandler
+
+// SubscribeProgress registers a handler that is called
+// with the upload progress while an update is submitted.
+func SubscribeProgress(handler ProgressHandler) {
+	progressHandlers = append(progressHandlers, handler)
+}
+
+func (progress UploadProgress) StatusDescription() string {
+	if progress.Finished {
+		return "Finished."
+	}
+	return steam.ItemUpdateStatusDescription[progress.Status]
+}
+
+func (progress UploadProgress) Percentage() int {
+	if progress.Finished {
+		return 100
+	}
+	if progress.BytesTotal == 0 {
+		return 0
+	}
+	return int(progress.BytesProcessed * 100 / progress.BytesTotal)
+}
+
+func reportProgress(progress UploadProgress) {
+	for _, handler := range progressHandlers {
+		handler(progress)
+	}
+}
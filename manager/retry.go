@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"bahmut.de/pdx-workshop-manager/logging"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// DefaultRetryAttempts is used if no retry-attempts are configured.
+const DefaultRetryAttempts = 3
+
+const maxRetryDelay = time.Minute
+
+var (
+	retryAttempts uint = DefaultRetryAttempts
+	// retryDelay is the wait before the second attempt, it doubles after every further failure
+	retryDelay = 5 * time.Second
+)
+
+// unappliedResults are the temporary failures after which steam has certainly not applied a call.
+// A call that timed out or lost its connection may have been applied anyway,
+// so calls that must not happen twice are only repeated after these results.
+var unappliedResults = []steam.EResult{
+	steam.K_EResultBusy,
+	steam.K_EResultRateLimitExceeded,
+	steam.K_EResultServiceUnavailable,
+}
+
+// retry calls the function until it succeeds, fails with an error that is not temporary,
+// the configured number of attempts is reached or the context is done.
+func retry[T any](ctx context.Context, operation string, call func() (T, error)) (T, error) {
+	return retryIf(ctx, operation, isRetryable, call)
+}
+
+// retryIf is retry with a custom check which errors are temporary.
+func retryIf[T any](ctx context.Context, operation string, retryable func(error) bool, call func() (T, error)) (T, error) {
+	delay := retryDelay
+	for attempt := uint(1); ; attempt++ {
+		result, err := call()
+		if err == nil || !retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+		if attempt >= retryAttempts {
+			return result, fmt.Errorf("%s failed after %d attempts: %w", operation, attempt, err)
+		}
+
+		logging.Warnf("%s failed, retrying in %s (attempt %d of %d): %v", operation, delay, attempt+1, retryAttempts, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, fmt.Errorf("%s was aborted while waiting to retry: %w", operation, context.Cause(ctx))
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// isRetryable reports if the error was caused by steam being temporarily unavailable.
+func isRetryable(err error) bool {
+	var resultError *steam.ResultError
+	return errors.As(err, &resultError) && resultError.Retryable()
+}
+
+// isUnapplied reports if the call failed temporarily before steam applied it.
+func isUnapplied(err error) bool {
+	var resultError *steam.ResultError
+	return errors.As(err, &resultError) && slices.Contains(unappliedResults, resultError.Result)
+}
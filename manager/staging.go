@@ -0,0 +1,267 @@
+package manager
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const IgnoreFileName = ".workshopignore"
+
+// defaultIgnorePatterns are never uploaded to the workshop.
+var defaultIgnorePatterns = []string{".git/", "/" + IgnoreFileName}
+
+// ignoreRule is a single gitignore style pattern.
+type ignoreRule struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	// anchored rules are matched against the whole relative path instead of the file name
+	anchored bool
+}
+
+type ignoreRules []ignoreRule
+
+func parseIgnoreRule(line string) (ignoreRule, bool, error) {
+	rule := ignoreRule{}
+
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false, nil
+	}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	// Escaped leading # or !
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		rule.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return rule, false, nil
+	}
+
+	rule.segments = strings.Split(line, "/")
+	for _, segment := range rule.segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return rule, false, fmt.Errorf("invalid ignore pattern '%s': %w", line, err)
+		}
+	}
+	return rule, true, nil
+}
+
+func (rule ignoreRule) matches(relative string, isDir bool) bool {
+	if rule.dirOnly && !isDir {
+		return false
+	}
+	if !rule.anchored {
+		matched, _ := path.Match(rule.segments[0], path.Base(relative))
+		return matched
+	}
+	return matchSegments(rule.segments, strings.Split(relative, "/"))
+}
+
+func matchSegments(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	// A trailing ** matches everything inside, but not the directory itself
+	if pattern[0] == "**" && len(pattern) == 1 {
+		return len(name) > 0
+	}
+	if pattern[0] == "**" {
+		for index := 0; index <= len(name); index++ {
+			if matchSegments(pattern[1:], name[index:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	matched, _ := path.Match(pattern[0], name[0])
+	return matched && matchSegments(pattern[1:], name[1:])
+}
+
+// ignored reports if a slash separated path relative to the mod directory is excluded,
+// the last matching rule decides.
+func (rules ignoreRules) ignored(relative string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.matches(relative, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// readIgnoreRules combines the default patterns, the .workshopignore file
+// in the mod directory and the configured exclude patterns.
+func readIgnoreRules(directory string, exclude []string) (ignoreRules, error) {
+	patterns := append([]string{}, defaultIgnorePatterns...)
+
+	ignoreFile, err := os.Open(filepath.Join(directory, IgnoreFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open %s: %w", IgnoreFileName, err)
+	}
+	if err == nil {
+		defer func(ignoreFile *os.File) {
+			_ = ignoreFile.Close()
+		}(ignoreFile)
+
+		scanner := bufio.NewScanner(ignoreFile)
+		for scanner.Scan() {
+			patterns = append(patterns, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+		}
+	}
+
+	patterns = append(patterns, exclude...)
+
+	rules := make(ignoreRules, 0, len(patterns))
+	for _, pattern := range patterns {
+		rule, ok, err := parseIgnoreRule(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// listContent returns the slash separated paths of all files in the directory
+// that are not excluded by the ignore rules.
+// Symbolic links are followed, linked files and directories are listed like regular ones.
+func listContent(directory string, rules ignoreRules) ([]string, error) {
+	root, err := filepath.EvalSymlinks(directory)
+	if err == nil {
+		var files []string
+		err = walkContent(root, "", rules, []string{root}, &files)
+		if err == nil {
+			return files, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to list mod content of %s: %w", directory, err)
+}
+
+// walkContent adds the files in the directory to the list, prefixing their paths with the given relative path.
+// Linked are the resolved directories that are walked already, linking back to one of them is a loop.
+func walkContent(directory string, prefix string, rules ignoreRules, linked []string, files *[]string) error {
+	return filepath.WalkDir(directory, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filePath == directory {
+			return nil
+		}
+		relative, err := filepath.Rel(directory, filePath)
+		if err != nil {
+			return err
+		}
+		relative = path.Join(prefix, filepath.ToSlash(relative))
+
+		isDir, isRegular := entry.IsDir(), entry.Type().IsRegular()
+		target := ""
+		if entry.Type()&fs.ModeSymlink != 0 {
+			target, err = filepath.EvalSymlinks(filePath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve symbolic link %s: %w", relative, err)
+			}
+			info, err := os.Stat(target)
+			if err != nil {
+				return fmt.Errorf("failed to resolve symbolic link %s: %w", relative, err)
+			}
+			isDir, isRegular = info.IsDir(), info.Mode().IsRegular()
+		}
+
+		if rules.ignored(relative, isDir) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if target != "" && isDir {
+			parent, err := filepath.EvalSymlinks(filepath.Dir(filePath))
+			if err != nil {
+				return err
+			}
+			if isWithin(parent, target) || slices.ContainsFunc(linked, func(walked string) bool {
+				return isWithin(walked, target)
+			}) {
+				return fmt.Errorf("symbolic link %s links to a directory containing itself", relative)
+			}
+			return walkContent(target, relative, rules, append(linked, target), files)
+		}
+		if isRegular {
+			*files = append(*files, relative)
+		}
+		return nil
+	})
+}
+
+// isWithin reports if the path is the directory itself or located in it.
+func isWithin(filePath string, directory string) bool {
+	relative, err := filepath.Rel(directory, filePath)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// stageContent copies the files of the mod into a new temporary directory,
+// which has to be removed by the caller.
+func stageContent(directory string, files []string) (string, error) {
+	staging, err := os.MkdirTemp("", "pdx-workshop-manager-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	for _, file := range files {
+		target := filepath.Join(staging, filepath.FromSlash(file))
+		err := os.MkdirAll(filepath.Dir(target), 0755)
+		if err == nil {
+			err = copyFile(filepath.Join(directory, filepath.FromSlash(file)), target)
+		}
+		if err != nil {
+			_ = os.RemoveAll(staging)
+			return "", fmt.Errorf("failed to stage %s: %w", file, err)
+		}
+	}
+	return staging, nil
+}
+
+func copyFile(source string, target string) error {
+	sourceFile, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func(sourceFile *os.File) {
+		_ = sourceFile.Close()
+	}(sourceFile)
+
+	targetFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(targetFile, sourceFile)
+	if closeErr := targetFile.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
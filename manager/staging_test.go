@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line string
+		want ignoreRule
+		ok   bool
+	}{
+		{line: "", ok: false},
+		{line: "# comment", ok: false},
+		{line: "   ", ok: false},
+		{line: "*.psd", want: ignoreRule{segments: []string{"*.psd"}}, ok: true},
+		{line: "*.psd  ", want: ignoreRule{segments: []string{"*.psd"}}, ok: true},
+		{line: "!keep.psd", want: ignoreRule{segments: []string{"keep.psd"}, negate: true}, ok: true},
+		{line: `\#file`, want: ignoreRule{segments: []string{"#file"}}, ok: true},
+		{line: `\!file`, want: ignoreRule{segments: []string{"!file"}}, ok: true},
+		{line: "build/", want: ignoreRule{segments: []string{"build"}, dirOnly: true}, ok: true},
+		{line: "/build", want: ignoreRule{segments: []string{"build"}, anchored: true}, ok: true},
+		{line: "docs/*.md", want: ignoreRule{segments: []string{"docs", "*.md"}, anchored: true}, ok: true},
+		{line: "**/temp/", want: ignoreRule{segments: []string{"**", "temp"}, dirOnly: true, anchored: true}, ok: true},
+		{line: "/", ok: false},
+	}
+	for _, test := range tests {
+		t.Run(test.line, func(t *testing.T) {
+			rule, ok, err := parseIgnoreRule(test.line)
+			if err != nil {
+				t.Fatalf("parseIgnoreRule(%q) error = %v", test.line, err)
+			}
+			if ok != test.ok {
+				t.Fatalf("parseIgnoreRule(%q) ok = %v, want %v", test.line, ok, test.ok)
+			}
+			if ok && !equalIgnoreRules(rule, test.want) {
+				t.Errorf("parseIgnoreRule(%q) = %+v, want %+v", test.line, rule, test.want)
+			}
+		})
+	}
+}
+
+func TestParseIgnoreRuleInvalidPattern(t *testing.T) {
+	_, _, err := parseIgnoreRule("[a-")
+	if err == nil {
+		t.Error("parseIgnoreRule() error = nil, want an invalid pattern error")
+	}
+}
+
+func equalIgnoreRules(rule ignoreRule, other ignoreRule) bool {
+	return slices.Equal(rule.segments, other.segments) &&
+		rule.negate == other.negate &&
+		rule.dirOnly == other.dirOnly &&
+		rule.anchored == other.anchored
+}
+
+func TestMatchSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/b/c", false},
+		{"a/*", "a/b", true},
+		{"a/*", "a/b/c", false},
+		{"**/b", "b", true},
+		{"**/b", "a/b", true},
+		{"**/b", "a/c/b", true},
+		{"**/b", "a/c", false},
+		{"a/**", "a/b/c", true},
+		{"a/**", "a/b", true},
+		{"a/**", "a", false},
+		{"a/**/c", "a/c", true},
+		{"a/**/c", "a/b/d/c", true},
+		{"a/**/c", "a/b/d", false},
+	}
+	for _, test := range tests {
+		got := matchSegments(strings.Split(test.pattern, "/"), strings.Split(test.name, "/"))
+		if got != test.want {
+			t.Errorf("matchSegments(%q, %q) = %v, want %v", test.pattern, test.name, got, test.want)
+		}
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		relative string
+		isDir    bool
+		want     bool
+	}{
+		// Unanchored patterns match the file name at any depth
+		{[]string{"*.psd"}, "gfx/icon.psd", false, true},
+		{[]string{"*.psd"}, "icon.dds", false, false},
+		// Anchored patterns match from the mod root
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "gfx/build", true, false},
+		{[]string{"docs/*.md"}, "docs/readme.md", false, true},
+		{[]string{"docs/*.md"}, "gfx/docs/readme.md", false, false},
+		{[]string{"**/docs/*.md"}, "gfx/docs/readme.md", false, true},
+		// Directory only patterns do not match files
+		{[]string{"temp/"}, "temp", true, true},
+		{[]string{"temp/"}, "temp", false, false},
+		{[]string{"temp/"}, "gfx/temp", true, true},
+		// The last matching rule decides
+		{[]string{"*.psd", "!keep.psd"}, "keep.psd", false, false},
+		{[]string{"*.psd", "!keep.psd"}, "other.psd", false, true},
+		{[]string{"!keep.psd", "*.psd"}, "keep.psd", false, true},
+		// A trailing /** excludes the content of a directory, so files in it can be included again
+		{[]string{"docs/**", "!docs/keep.md"}, "docs", true, false},
+		{[]string{"docs/**", "!docs/keep.md"}, "docs/keep.md", false, false},
+		{[]string{"docs/**", "!docs/keep.md"}, "docs/other.md", false, true},
+	}
+	for _, test := range tests {
+		var rules ignoreRules
+		for _, pattern := range test.patterns {
+			rule, ok, err := parseIgnoreRule(pattern)
+			if err != nil || !ok {
+				t.Fatalf("parseIgnoreRule(%q) = %v, %v", pattern, ok, err)
+			}
+			rules = append(rules, rule)
+		}
+		got := rules.ignored(test.relative, test.isDir)
+		if got != test.want {
+			t.Errorf("%q ignored(%q, dir %v) = %v, want %v", test.patterns, test.relative, test.isDir, got, test.want)
+		}
+	}
+}
+
+func TestListContentFollowsSymbolicLinks(t *testing.T) {
+	directory := t.TempDir()
+	shared := filepath.Join(directory, "shared")
+	mod := filepath.Join(directory, "mod")
+	writeTestFile(t, filepath.Join(shared, "gfx", "shared.dds"), "shared")
+	writeTestFile(t, filepath.Join(shared, "single.txt"), "single")
+	writeTestFile(t, filepath.Join(mod, "common", "mod.txt"), "mod")
+	writeTestFile(t, filepath.Join(mod, "ignored", "file.txt"), "ignored")
+
+	for link, target := range map[string]string{
+		filepath.Join(mod, "linked"):             shared,
+		filepath.Join(mod, "common", "link.txt"): filepath.Join(shared, "single.txt"),
+		filepath.Join(mod, "ignored-link"):       shared,
+	} {
+		if err := os.Symlink(target, link); err != nil {
+			t.Skipf("symbolic links are not supported: %v", err)
+		}
+	}
+
+	rules, err := readIgnoreRules(mod, []string{"ignored/", "/ignored-link/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := listContent(mod, rules)
+	if err != nil {
+		t.Fatalf("listContent() error = %v", err)
+	}
+
+	want := []string{"common/link.txt", "common/mod.txt", "linked/gfx/shared.dds", "linked/single.txt"}
+	slices.Sort(files)
+	if !slices.Equal(files, want) {
+		t.Errorf("listContent() = %q, want %q", files, want)
+	}
+}
+
+func TestListContentDetectsSymbolicLinkLoops(t *testing.T) {
+	mod := t.TempDir()
+	writeTestFile(t, filepath.Join(mod, "common", "mod.txt"), "mod")
+	if err := os.Symlink(filepath.Join(mod, "common"), filepath.Join(mod, "common", "loop")); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+
+	_, err := listContent(mod, nil)
+	if err == nil || !strings.Contains(err.Error(), "common/loop") {
+		t.Errorf("listContent() error = %v, want a loop error for common/loop", err)
+	}
+}
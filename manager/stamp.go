@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+const (
+	maxKeyValueTagLength = 255
+	maxItemMetadataSize  = 5000
+)
+
+var keyValueTagKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// stampMod renders the configured key-value tags and item metadata string.
+func stampMod(data *ModUploadData, modConfig *config.ModConfig, renderer *templateRenderer) error {
+	if modConfig.KeyValueTags != nil {
+		data.KeyValueTags = make(map[string]string, len(modConfig.KeyValueTags))
+		for key, text := range modConfig.KeyValueTags {
+			if !keyValueTagKeyPattern.MatchString(key) || len(key) > maxKeyValueTagLength {
+				return fmt.Errorf("invalid key-value tag key '%s': only up to %d letters, digits and underscores are allowed", key, maxKeyValueTagLength)
+			}
+			value, err := renderer.render(key, text, steam.English)
+			if err != nil {
+				return err
+			}
+			if len(value) > maxKeyValueTagLength {
+				return fmt.Errorf("value of key-value tag '%s' is longer than %d characters", key, maxKeyValueTagLength)
+			}
+			data.KeyValueTags[key] = value
+		}
+	}
+
+	if modConfig.Metadata != "" {
+		metadata, err := renderer.render("metadata", modConfig.Metadata, steam.English)
+		if err != nil {
+			return err
+		}
+		if len(metadata) > maxItemMetadataSize {
+			return fmt.Errorf("item metadata is larger than %d bytes", maxItemMetadataSize)
+		}
+		data.ItemMetadata = metadata
+	}
+
+	return nil
+}
@@ -0,0 +1,433 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// SteamBackend publishes to the steam workshop through the steamworks API.
+// The steam API has to be initialized using Init before it is used.
+// All steam calls run on the thread of the callback dispatcher started by Init.
+type SteamBackend struct{}
+
+func (b *SteamBackend) CreateItem(ctx context.Context, game uint) (uint64, error) {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().CreateItem(
+			game,
+			steam.K_EWorkshopFileTypeCommunity,
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.CreateItemResultType, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer steam.DeleteCreateItemResult_t(result)
+
+	if result.GetM_bUserNeedsToAcceptWorkshopLegalAgreement() {
+		return 0, ErrLegalAgreement
+	}
+
+	return result.GetM_nPublishedFileId(), nil
+}
+
+func (b *SteamBackend) SubmitItemUpdate(ctx context.Context, update *ItemUpdate) error {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var handle, apiCall uint64
+	err = dispatcher.Run(func() {
+		handle = steam.SteamUGC().StartItemUpdate(update.Game, update.Identifier)
+
+		if update.Title != "" {
+			steam.SteamUGC().SetItemTitle(handle, update.Title)
+		}
+
+		if update.Description != "" {
+			steam.SteamUGC().SetItemDescription(handle, update.Description)
+		}
+
+		if update.ContentPath != "" {
+			steam.SteamUGC().SetItemContent(handle, update.ContentPath)
+		}
+
+		if update.PreviewPath != "" {
+			steam.SteamUGC().SetItemPreview(handle, update.PreviewPath)
+		}
+
+		if update.Visibility != "" {
+			steam.SteamUGC().SetItemVisibility(handle, update.Visibility.GetValue())
+		}
+
+		for index, path := range update.UpdatePreviewFiles {
+			steam.SteamUGC().UpdateItemPreviewFile(handle, index, path)
+		}
+
+		// Remove from the back, so the remaining indexes stay valid
+		removals := slices.Clone(update.RemovePreviews)
+		slices.Sort(removals)
+		slices.Reverse(removals)
+		for _, index := range removals {
+			steam.SteamUGC().RemoveItemPreview(handle, index)
+		}
+
+		for _, path := range update.AddPreviewFiles {
+			steam.SteamUGC().AddItemPreviewFile(handle, path, steam.K_EItemPreviewType_Image)
+		}
+
+		for _, video := range update.AddPreviewVideos {
+			steam.SteamUGC().AddItemPreviewVideo(handle, video)
+		}
+
+		if len(update.Tags) > 0 {
+			tagArray := steam.NewSteamParamStringArray(update.Tags)
+			steam.SteamUGC().SetItemTagsExtension(handle, tagArray)
+		}
+
+		if update.KeyValueTags != nil {
+			steam.SteamUGC().RemoveAllItemKeyValueTags(handle)
+			for _, key := range slices.Sorted(maps.Keys(update.KeyValueTags)) {
+				steam.SteamUGC().AddItemKeyValueTag(handle, key, update.KeyValueTags[key])
+			}
+		}
+
+		if update.Metadata != "" {
+			steam.SteamUGC().SetItemMetadata(handle, update.Metadata)
+		}
+
+		if update.MinGameBranch != "" || update.MaxGameBranch != "" {
+			steam.SteamUGC().SetRequiredGameVersions(handle, update.MinGameBranch, update.MaxGameBranch)
+		}
+
+		steam.SteamUGC().SetItemUpdateLanguage(handle, update.Language.GetString())
+
+		apiCall = steam.SteamUGC().SubmitItemUpdate(handle, update.ChangeNote)
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.SubmitItemUpdateResultType, func() {
+		var bytesProcessed, bytesTotal uint64
+		status := steam.SteamUGC().GetItemUpdateProgress(handle, &bytesProcessed, &bytesTotal)
+		reportProgress(UploadProgress{
+			Identifier:     update.Identifier,
+			Language:       update.Language,
+			Status:         status,
+			BytesProcessed: bytesProcessed,
+			BytesTotal:     bytesTotal,
+		})
+	})
+
+	reportProgress(UploadProgress{
+		Identifier: update.Identifier,
+		Language:   update.Language,
+		Finished:   true,
+	})
+
+	if err != nil {
+		return err
+	}
+	steam.DeleteSubmitItemUpdateResult_t(result)
+
+	return nil
+}
+
+func (b *SteamBackend) QueryItem(ctx context.Context, game uint, identifier uint64) (*ItemDetails, error) {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var query, apiCall uint64
+	err = dispatcher.Run(func() {
+		identifiers := []uint64{identifier}
+		query = steam.SteamUGC().CreateQueryUGCDetailsRequest(&identifiers[0], 1)
+
+		steam.SteamUGC().SetReturnAdditionalPreviews(query, true)
+		steam.SteamUGC().SetReturnChildren(query, true)
+		steam.SteamUGC().SetReturnKeyValueTags(query, true)
+		steam.SteamUGC().SetReturnMetadata(query, true)
+
+		apiCall = steam.SteamUGC().SendQueryUGCRequest(query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer dispatcher.Do(func() {
+		steam.SteamUGC().ReleaseQueryUGCRequest(query)
+	})
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.SteamUGCQueryCompletedType, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer steam.DeleteSteamUGCQueryCompleted_t(result)
+
+	if result.GetM_unNumResultsReturned() == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrItemNotFound, identifier)
+	}
+
+	var item *ItemDetails
+	runErr := dispatcher.Run(func() {
+		item, err = readQueryResult(result.GetM_handle(), game, identifier)
+	})
+	if runErr != nil {
+		return nil, runErr
+	}
+	return item, err
+}
+
+// readQueryResult reads the first item of a completed query, it has to run on the dispatcher thread.
+func readQueryResult(query uint64, game uint, identifier uint64) (*ItemDetails, error) {
+	details := steam.NewSteamUGCDetails_t()
+	defer steam.DeleteSteamUGCDetails_t(details)
+	if !steam.SteamUGC().GetQueryUGCResult(query, 0, details) {
+		return nil, fmt.Errorf("failed to read workshop item %d", identifier)
+	}
+
+	if details.GetM_eResult() != steam.K_EResultOK {
+		return nil, fmt.Errorf("failed to query workshop item %d: %w", identifier, steam.NewResultError(details.GetM_eResult()))
+	}
+
+	if details.GetM_nConsumerAppID() != game {
+		return nil, fmt.Errorf("%w: %d is not an item of game %d", ErrWrongGame, identifier, game)
+	}
+
+	var tags []string
+	if details.GetM_rgchTags() != "" {
+		tags = strings.Split(details.GetM_rgchTags(), ",")
+	}
+
+	previewCount := steam.SteamUGC().GetQueryUGCNumAdditionalPreviews(query, 0)
+	previews := make([]ItemPreview, 0, previewCount)
+	for index := uint(0); index < previewCount; index++ {
+		source, fileName, previewType, ok := steam.SteamUGC().GetQueryUGCAdditionalPreviewExtension(query, 0, index)
+		if !ok {
+			return nil, fmt.Errorf("failed to read preview %d of workshop item %d", index, identifier)
+		}
+		previews = append(previews, ItemPreview{
+			Index:    index,
+			Type:     previewType,
+			Source:   source,
+			FileName: fileName,
+		})
+	}
+
+	children := make([]uint64, details.GetM_unNumChildren())
+	if len(children) > 0 && !steam.SteamUGC().GetQueryUGCChildren(query, 0, &children[0], uint(len(children))) {
+		return nil, fmt.Errorf("failed to read dependencies of workshop item %d", identifier)
+	}
+
+	keyValueTagCount := steam.SteamUGC().GetQueryUGCNumKeyValueTags(query, 0)
+	keyValueTags := make(map[string]string, keyValueTagCount)
+	for index := uint(0); index < keyValueTagCount; index++ {
+		key, value, ok := steam.SteamUGC().GetQueryUGCKeyValueTagExtension(query, 0, index)
+		if !ok {
+			return nil, fmt.Errorf("failed to read key-value tag %d of workshop item %d", index, identifier)
+		}
+		keyValueTags[key] = value
+	}
+
+	metadata, ok := steam.SteamUGC().GetQueryUGCMetadataExtension(query, 0)
+	if !ok {
+		return nil, fmt.Errorf("failed to read metadata of workshop item %d", identifier)
+	}
+
+	return &ItemDetails{
+		Identifier:   details.GetM_nPublishedFileId(),
+		Title:        details.GetM_rgchTitle(),
+		Description:  details.GetM_rgchDescription(),
+		Tags:         tags,
+		KeyValueTags: keyValueTags,
+		Metadata:     metadata,
+		Visibility:   steam.VisibilityFromValue(details.GetM_eVisibility()),
+		Previews:     previews,
+		Children:     children,
+	}, nil
+}
+
+func (b *SteamBackend) AddDependency(ctx context.Context, parent uint64, child uint64) error {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().AddDependency(parent, child)
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.AddUGCDependencyResultType, nil)
+	if err != nil {
+		return err
+	}
+	defer steam.DeleteAddUGCDependencyResult_t(result)
+
+	return nil
+}
+
+func (b *SteamBackend) RemoveDependency(ctx context.Context, parent uint64, child uint64) error {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().RemoveDependency(parent, child)
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.RemoveUGCDependencyResultType, nil)
+	if err != nil {
+		return err
+	}
+	defer steam.DeleteRemoveUGCDependencyResult_t(result)
+
+	return nil
+}
+
+func (b *SteamBackend) GetAppDependencies(ctx context.Context, identifier uint64) ([]uint, error) {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().GetAppDependencies(identifier)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.GetAppDependenciesResultType, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer steam.DeleteGetAppDependenciesResult_t(result)
+
+	if result.GetM_nTotalNumAppDependencies() > result.GetM_nNumAppDependencies() {
+		return nil, fmt.Errorf("workshop item %d has more app dependencies than can be read at once", identifier)
+	}
+
+	return result.GetAppIDsExtension(), nil
+}
+
+func (b *SteamBackend) AddAppDependency(ctx context.Context, identifier uint64, app uint) error {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().AddAppDependency(identifier, app)
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.AddAppDependencyResultType, nil)
+	if err != nil {
+		return err
+	}
+	defer steam.DeleteAddAppDependencyResult_t(result)
+
+	return nil
+}
+
+func (b *SteamBackend) RemoveAppDependency(ctx context.Context, identifier uint64, app uint) error {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().RemoveAppDependency(identifier, app)
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.RemoveAppDependencyResultType, nil)
+	if err != nil {
+		return err
+	}
+	defer steam.DeleteRemoveAppDependencyResult_t(result)
+
+	return nil
+}
+
+func (b *SteamBackend) DeleteItem(ctx context.Context, identifier uint64) error {
+	dispatcher, err := steamDispatcher()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := withCallTimeout(ctx)
+	defer cancel()
+
+	var apiCall uint64
+	err = dispatcher.Run(func() {
+		apiCall = steam.SteamUGC().DeleteItem(identifier)
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := steam.AwaitCallResult(ctx, dispatcher, apiCall, steam.DeleteItemResultType, nil)
+	if err != nil {
+		return err
+	}
+	defer steam.DeleteDeleteItemResult_t(result)
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+// Included snippets may include further snippets up to this depth
+const maxIncludeDepth = 10
+
+// TemplateData is available in descriptions, change notes, key-value tags and the item metadata.
+type TemplateData struct {
+	Name                 string
+	Version              string
+	SupportedGameVersion string
+	Tags                 []string
+	// Identifier is the workshop id, 0 when a new mod is checked before its first upload
+	Identifier uint64
+	// Date is the day of the upload in UTC like the timestamp, e.g. 2024-05-01
+	Date     string
+	Language steam.ApiLanguage
+}
+
+// templateRenderer renders all templates of a single mod upload.
+type templateRenderer struct {
+	metadata         *ModMetadata
+	identifier       uint64
+	directory        string
+	includeDirectory string
+	preparedAt       time.Time
+}
+
+func newTemplateRenderer(metadata *ModMetadata, identifier uint64, directory string, includeDirectory string) *templateRenderer {
+	return &templateRenderer{
+		metadata:         metadata,
+		identifier:       identifier,
+		directory:        directory,
+		includeDirectory: includeDirectory,
+		preparedAt:       time.Now(),
+	}
+}
+
+// render executes the text as template for the given language.
+// The functions commit and timestamp return the git commit of the mod directory
+// and the time the upload was prepared, include renders a shared snippet.
+func (renderer *templateRenderer) render(name string, text string, language steam.ApiLanguage) (string, error) {
+	return renderer.renderDepth(name, text, language, 0)
+}
+
+func (renderer *templateRenderer) renderDepth(name string, text string, language steam.ApiLanguage, depth int) (string, error) {
+	parsed, err := template.New(name).Funcs(template.FuncMap{
+		"commit": func() (string, error) {
+			return gitCommit(renderer.directory)
+		},
+		"timestamp": func() string {
+			return renderer.preparedAt.UTC().Format(time.RFC3339)
+		},
+		"include": func(snippet string) (string, error) {
+			if depth >= maxIncludeDepth {
+				return "", fmt.Errorf("snippet %s is included more than %d times deep", snippet, maxIncludeDepth)
+			}
+			path, content, err := renderer.readInclude(snippet, language)
+			if err != nil {
+				return "", err
+			}
+			return renderer.renderDepth(path, content, language, depth+1)
+		},
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s template, write {{\"{{\"}} for literal braces: %w", name, err)
+	}
+
+	var builder strings.Builder
+	err = parsed.Execute(&builder, TemplateData{
+		Name:                 renderer.metadata.Name,
+		Version:              renderer.metadata.Version,
+		SupportedGameVersion: renderer.metadata.SupportedGameVersion,
+		Tags:                 renderer.metadata.Tags,
+		Identifier:           renderer.identifier,
+		Date:                 renderer.preparedAt.UTC().Format(time.DateOnly),
+		Language:             language,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s template: %w", name, err)
+	}
+	return builder.String(), nil
+}
+
+// readInclude reads a snippet from the include directory,
+// preferring a localized snippet in the subdirectory of the language.
+func (renderer *templateRenderer) readInclude(snippet string, language steam.ApiLanguage) (string, string, error) {
+	if renderer.includeDirectory == "" {
+		return "", "", fmt.Errorf("failed to include %s: no include-directory is configured", snippet)
+	}
+	if !filepath.IsLocal(snippet) {
+		return "", "", fmt.Errorf("failed to include %s: snippets have to be located in the include-directory", snippet)
+	}
+
+	for _, path := range []string{
+		filepath.Join(renderer.includeDirectory, language.GetString(), snippet),
+		filepath.Join(renderer.includeDirectory, snippet),
+	} {
+		content, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("failed to include %s: %w", snippet, err)
+		}
+		return path, string(content), nil
+	}
+	return "", "", fmt.Errorf("failed to include %s: snippet not found in %s", snippet, renderer.includeDirectory)
+}
+
+func gitCommit(directory string) (string, error) {
+	output, err := exec.Command("git", "-C", directory, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read git commit of %s: %w", directory, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+func TestRenderLiteralBraces(t *testing.T) {
+	renderer := newTemplateRenderer(&ModMetadata{Name: "Test Mod"}, 1, t.TempDir(), "")
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: `Use {{"{{"}}key}} in {{.Name}}`, want: "Use {{key}} in Test Mod"},
+		{text: "Closing }} braces are kept", want: "Closing }} braces are kept"},
+	}
+	for _, test := range tests {
+		got, err := renderer.render("description.bbcode", test.text, steam.English)
+		if err != nil {
+			t.Fatalf("render(%q) error = %v", test.text, err)
+		}
+		if got != test.want {
+			t.Errorf("render(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+
+	_, err := renderer.render("description.bbcode", "Use {{key}}", steam.English)
+	if err == nil || !strings.Contains(err.Error(), `{{"{{"}}`) {
+		t.Errorf("render() error = %v, want a parse error explaining how to escape braces", err)
+	}
+}
+
+func TestRenderDateMatchesTimestamp(t *testing.T) {
+	renderer := newTemplateRenderer(&ModMetadata{}, 1, t.TempDir(), "")
+	// Shortly before midnight in UTC it is already the next day in zones east of UTC
+	renderer.preparedAt = time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC).In(time.FixedZone("UTC+2", 2*60*60))
+
+	got, err := renderer.render("description.bbcode", "{{.Date}} {{timestamp}}", steam.English)
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if want := "2024-05-01 2024-05-01T23:30:00Z"; got != want {
+		t.Errorf("render() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,183 @@
+package manager
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"slices"
+
+	"bahmut.de/pdx-workshop-manager/logging"
+)
+
+// MaxPreviewSize is the size steam preview images have to stay below.
+const MaxPreviewSize = 1024 * 1024
+
+var thumbnailFormats = []string{"png", "jpeg", "gif"}
+
+// Thumbnails deviating further from a square aspect ratio are reported
+const thumbnailAspectTolerance = 0.01
+
+const (
+	thumbnailJpegQuality    = 90
+	thumbnailScaleStep      = 0.75
+	thumbnailMaxConversions = 10
+)
+
+// checkThumbnail decodes the thumbnail and validates it against the steam preview limits.
+// It returns true if the thumbnail is too large and has to be converted before the upload,
+// which is only allowed if convert is enabled.
+func checkThumbnail(path string, convert bool) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open steam thumbnail: %w", err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	imageConfig, format, err := image.DecodeConfig(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode steam thumbnail %s: %w", path, err)
+	}
+	if !slices.Contains(thumbnailFormats, format) {
+		return false, fmt.Errorf("unsupported steam thumbnail format '%s', use png, jpeg or gif", format)
+	}
+	if imageConfig.Width == 0 || imageConfig.Height == 0 {
+		return false, fmt.Errorf("steam thumbnail %s is empty", path)
+	}
+
+	ratio := float64(imageConfig.Width) / float64(imageConfig.Height)
+	if math.Abs(ratio-1) > thumbnailAspectTolerance {
+		logging.Warnf("steam thumbnail %s is %dx%d and may be cropped on the workshop, use a square image", path, imageConfig.Width, imageConfig.Height)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("failed to read steam thumbnail size: %w", err)
+	}
+	if info.Size() < MaxPreviewSize {
+		return false, nil
+	}
+	if !convert {
+		return false, fmt.Errorf("steam thumbnail %s is %d KB, steam only accepts less than %d KB (enable convert-thumbnail to convert it automatically)", path, info.Size()/1024, MaxPreviewSize/1024)
+	}
+	if format == "gif" {
+		return false, fmt.Errorf("steam thumbnail %s is %d KB, animated gif thumbnails can not be converted", path, info.Size()/1024)
+	}
+	return true, nil
+}
+
+// convertThumbnail re-encodes the thumbnail as jpeg into a temporary file,
+// downscaling it until it is below the steam preview limit.
+// The temporary file has to be removed by the caller.
+func convertThumbnail(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open steam thumbnail: %w", err)
+	}
+	source, _, err := image.Decode(file)
+	_ = file.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode steam thumbnail %s: %w", path, err)
+	}
+
+	// Jpeg has no transparency, so transparent areas become white
+	flattened := image.NewRGBA(source.Bounds())
+	draw.Draw(flattened, flattened.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flattened, flattened.Bounds(), source, source.Bounds().Min, draw.Over)
+
+	scale := 1.0
+	for range thumbnailMaxConversions {
+		width := max(1, int(float64(flattened.Bounds().Dx())*scale))
+		height := max(1, int(float64(flattened.Bounds().Dy())*scale))
+
+		var encoded bytes.Buffer
+		err := jpeg.Encode(&encoded, downscale(flattened, width, height), &jpeg.Options{Quality: thumbnailJpegQuality})
+		if err != nil {
+			return "", fmt.Errorf("failed to encode steam thumbnail: %w", err)
+		}
+
+		if encoded.Len() < MaxPreviewSize {
+			converted, err := os.CreateTemp("", "pdx-workshop-manager-thumbnail-*.jpg")
+			if err != nil {
+				return "", fmt.Errorf("failed to create converted steam thumbnail: %w", err)
+			}
+			_, err = converted.Write(encoded.Bytes())
+			if closeErr := converted.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				_ = os.Remove(converted.Name())
+				return "", fmt.Errorf("failed to write converted steam thumbnail: %w", err)
+			}
+			logging.Infof("Converted steam thumbnail to %dx%d jpeg (%d KB)", width, height, encoded.Len()/1024)
+			return converted.Name(), nil
+		}
+		scale *= thumbnailScaleStep
+	}
+
+	return "", fmt.Errorf("failed to convert steam thumbnail %s below %d KB", path, MaxPreviewSize/1024)
+}
+
+// previewThumbnail returns the thumbnail path to upload, converting the thumbnail if necessary.
+// The returned function removes a converted thumbnail again.
+func previewThumbnail(data *ModUploadData, thumbnailPath string) (string, func(), error) {
+	if !data.ConvertThumbnail {
+		return thumbnailPath, func() {}, nil
+	}
+
+	converted, err := convertThumbnail(thumbnailPath)
+	if err != nil {
+		return "", nil, err
+	}
+	return converted, func() {
+		err := os.Remove(converted)
+		if err != nil {
+			logging.Warnf("failed to remove converted steam thumbnail %s: %v", converted, err)
+		}
+	}, nil
+}
+
+// downscale resizes the image by averaging all source pixels covered by a target pixel.
+func downscale(source *image.RGBA, width int, height int) *image.RGBA {
+	bounds := source.Bounds()
+	if width >= bounds.Dx() && height >= bounds.Dy() {
+		return source
+	}
+
+	target := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		top := bounds.Min.Y + y*bounds.Dy()/height
+		bottom := max(top+1, bounds.Min.Y+(y+1)*bounds.Dy()/height)
+		for x := range width {
+			left := bounds.Min.X + x*bounds.Dx()/width
+			right := max(left+1, bounds.Min.X+(x+1)*bounds.Dx()/width)
+
+			var r, g, b, a, count uint32
+			for sourceY := top; sourceY < bottom; sourceY++ {
+				for sourceX := left; sourceX < right; sourceX++ {
+					pixel := source.RGBAAt(sourceX, sourceY)
+					r += uint32(pixel.R)
+					g += uint32(pixel.G)
+					b += uint32(pixel.B)
+					a += uint32(pixel.A)
+					count++
+				}
+			}
+			target.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / count),
+				G: uint8(g / count),
+				B: uint8(b / count),
+				A: uint8(a / count),
+			})
+		}
+	}
+	return target
+}
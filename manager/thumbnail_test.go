@@ -0,0 +1,50 @@
+package manager
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePaddedThumbnail writes a png of exactly the given size by padding it after the image data.
+func writePaddedThumbnail(t *testing.T, path string, size int) {
+	t.Helper()
+	var encoded bytes.Buffer
+	err := png.Encode(&encoded, image.NewRGBA(image.Rect(0, 0, 16, 16)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded.Write(make([]byte, size-encoded.Len()))
+	err = os.WriteFile(path, encoded.Bytes(), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckThumbnailSizeLimit(t *testing.T) {
+	tests := []struct {
+		size    int
+		convert bool
+		want    bool
+		wantErr bool
+	}{
+		{size: MaxPreviewSize - 1, convert: false, want: false},
+		{size: MaxPreviewSize, convert: false, wantErr: true},
+		{size: MaxPreviewSize, convert: true, want: true},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "thumbnail.png")
+		writePaddedThumbnail(t, path, test.size)
+
+		got, err := checkThumbnail(path, test.convert)
+		if (err != nil) != test.wantErr {
+			t.Errorf("checkThumbnail(%d bytes, convert %v) error = %v, want error %v", test.size, test.convert, err, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("checkThumbnail(%d bytes, convert %v) = %v, want %v", test.size, test.convert, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultCallTimeout is used if no call-timeout is configured.
+const DefaultCallTimeout = 30 * time.Minute
+
+// ErrCallTimeout is the cause of steam API calls that did not complete within the call timeout.
+var ErrCallTimeout = errors.New("steam API call timed out")
+
+var callTimeout = DefaultCallTimeout
+
+// withCallTimeout limits how long a single steam API call is waited for.
+func withCallTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, callTimeout, ErrCallTimeout)
+}
@@ -0,0 +1,217 @@
+package manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"bahmut.de/pdx-workshop-manager/bbcode"
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/logging"
+)
+
+type VersionPart string
+
+const (
+	Major VersionPart = "major"
+	Minor VersionPart = "minor"
+	Patch VersionPart = "patch"
+)
+
+func (part VersionPart) IsValid() bool {
+	return part == Major || part == Minor || part == Patch
+}
+
+// BumpVersion increases the version in the metadata.json of the mod and returns the new version.
+// Only the version is replaced, everything else including the byte order mark is kept.
+// The metadata is not changed if there is no change note for the new version.
+func BumpVersion(modConfig *config.ModConfig, part VersionPart) (string, error) {
+	versions, err := BumpVersions([]*config.ModConfig{modConfig}, part)
+	if err != nil {
+		return "", err
+	}
+	return versions[0], nil
+}
+
+// BumpVersions increases the versions of all mods like BumpVersion and returns the new versions in order.
+// The metadata files are only written once every mod has a valid version and a change note for the new version,
+// so a failing mod does not leave the others bumped. If writing a file fails, the files written before are restored.
+func BumpVersions(modConfigs []*config.ModConfig, part VersionPart) ([]string, error) {
+	versions := make([]string, 0, len(modConfigs))
+	bumps := make([]*versionBump, 0, len(modConfigs))
+	for _, modConfig := range modConfigs {
+		bump, err := prepareVersionBump(modConfig, part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bump version of mod %d (%s): %w", modConfig.Identifier, modConfig.Directory, err)
+		}
+		versions = append(versions, bump.version)
+		bumps = append(bumps, bump)
+	}
+
+	for index, bump := range bumps {
+		err := os.WriteFile(bump.path, bump.updated, bump.mode)
+		if err == nil {
+			continue
+		}
+		for _, written := range bumps[:index] {
+			if restoreErr := os.WriteFile(written.path, written.original, written.mode); restoreErr != nil {
+				logging.Warnf("failed to restore metadata file %s: %v", written.path, restoreErr)
+			}
+		}
+		return nil, fmt.Errorf("failed to write metadata file %s: %w", bump.path, err)
+	}
+	return versions, nil
+}
+
+// versionBump is the updated content of a metadata file that is not written yet.
+type versionBump struct {
+	path     string
+	mode     os.FileMode
+	original []byte
+	updated  []byte
+	version  string
+}
+
+// prepareVersionBump reads the metadata of the mod and replaces the version without writing the file.
+func prepareVersionBump(modConfig *config.ModConfig, part VersionPart) (*versionBump, error) {
+	metadataPath := filepath.Join(modConfig.Directory, ".metadata", "metadata.json")
+	info, err := os.Stat(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+	content, err := os.ReadFile(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %w", err)
+	}
+
+	start, end, err := findVersion(content)
+	if err != nil {
+		return nil, err
+	}
+	var version string
+	err = json.Unmarshal(content[start:end], &version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata version: %w", err)
+	}
+
+	bumped, err := bumpVersion(version, part)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasChangeNote(modConfig, bumped) {
+		return nil, fmt.Errorf("refusing to bump version to %s: no change note exists for the new version", bumped)
+	}
+
+	encoded, err := json.Marshal(bumped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata version: %w", err)
+	}
+
+	updated := make([]byte, 0, len(content)+len(encoded))
+	updated = append(updated, content[:start]...)
+	updated = append(updated, encoded...)
+	updated = append(updated, content[end:]...)
+
+	return &versionBump{
+		path:     metadataPath,
+		mode:     info.Mode().Perm(),
+		original: content,
+		updated:  updated,
+		version:  bumped,
+	}, nil
+}
+
+// findVersion returns the byte range of the top level version string in the metadata,
+// ignoring versions of relationships.
+func findVersion(content []byte) (int, int, error) {
+	bom := len(content) - len(bytes.TrimPrefix(content, []byte("\uFEFF")))
+	decoder := json.NewDecoder(bytes.NewReader(content[bom:]))
+
+	token, err := decoder.Token()
+	if err != nil || token != json.Delim('{') {
+		return 0, 0, errors.New("failed to parse metadata file: expected a json object")
+	}
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse metadata file: %w", err)
+		}
+
+		valueStart := decoder.InputOffset()
+		var value json.RawMessage
+		err = decoder.Decode(&value)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse metadata file: %w", err)
+		}
+		if key != "version" {
+			continue
+		}
+
+		// The offset before the value includes the colon and whitespace
+		valueEnd := int(decoder.InputOffset())
+		quote := bytes.IndexByte(content[bom+int(valueStart):bom+valueEnd], '"')
+		if quote < 0 || !bytes.HasPrefix(value, []byte(`"`)) {
+			return 0, 0, errors.New("failed to parse metadata file: version is not a string")
+		}
+		return bom + int(valueStart) + quote, bom + valueEnd, nil
+	}
+	return 0, 0, errors.New("failed to find version in metadata file")
+}
+
+// bumpVersion increases a version like 1.2.3 or v1.2.3, resetting the lower parts.
+func bumpVersion(version string, part VersionPart) (string, error) {
+	prefix := ""
+	if strings.HasPrefix(version, "v") {
+		prefix = "v"
+	}
+
+	parts := strings.Split(strings.TrimPrefix(version, prefix), ".")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("failed to bump version '%s': expected major.minor.patch", version)
+	}
+	numbers := make([]int, 3)
+	for index, value := range parts {
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return "", fmt.Errorf("failed to bump version '%s': expected major.minor.patch", version)
+		}
+		numbers[index] = number
+	}
+
+	switch part {
+	case Major:
+		numbers = []int{numbers[0] + 1, 0, 0}
+	case Minor:
+		numbers = []int{numbers[0], numbers[1] + 1, 0}
+	case Patch:
+		numbers = []int{numbers[0], numbers[1], numbers[2] + 1}
+	default:
+		return "", fmt.Errorf("invalid version part: %s", part)
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, numbers[0], numbers[1], numbers[2]), nil
+}
+
+// hasChangeNote reports if any configured change note directory or changelog
+// contains a change note for the version.
+func hasChangeNote(modConfig *config.ModConfig, version string) bool {
+	for _, directory := range modConfig.ChangeNoteDirectories {
+		for _, extension := range []string{".bbcode", bbcode.MarkdownExtension} {
+			if _, err := os.Stat(filepath.Join(directory, version+extension)); err == nil {
+				return true
+			}
+		}
+	}
+	for _, changelog := range modConfig.Changelogs {
+		if _, err := readChangelogSection(changelog, version); err == nil {
+			return true
+		}
+	}
+	return false
+}
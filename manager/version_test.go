@@ -0,0 +1,164 @@
+package manager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bahmut.de/pdx-workshop-manager/config"
+	"bahmut.de/pdx-workshop-manager/steam"
+)
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		part     VersionPart
+		version  string
+		want     string
+	}{
+		{
+			name:     "patch",
+			metadata: `{"name": "Mod", "version": "1.2.3"}`,
+			part:     Patch,
+			version:  "1.2.4",
+			want:     `{"name": "Mod", "version": "1.2.4"}`,
+		},
+		{
+			name:     "byte order mark",
+			metadata: "\uFEFF" + `{"version": "1.2.3", "name": "Mod"}`,
+			part:     Minor,
+			version:  "1.3.0",
+			want:     "\uFEFF" + `{"version": "1.3.0", "name": "Mod"}`,
+		},
+		{
+			name:     "whitespace around colon",
+			metadata: "{\n\t\"version\"  :\n\t\t\"1.2.3\" ,\n\t\"name\": \"Mod\"\n}",
+			part:     Major,
+			version:  "2.0.0",
+			want:     "{\n\t\"version\"  :\n\t\t\"2.0.0\" ,\n\t\"name\": \"Mod\"\n}",
+		},
+		{
+			name:     "relationship version",
+			metadata: `{"relationships": [{"id": "1", "version": "1.2.3"}], "version": "1.2.3"}`,
+			part:     Patch,
+			version:  "1.2.4",
+			want:     `{"relationships": [{"id": "1", "version": "1.2.3"}], "version": "1.2.4"}`,
+		},
+		{
+			name:     "v prefix",
+			metadata: `{"version": "v1.2.3"}`,
+			part:     Minor,
+			version:  "v1.3.0",
+			want:     `{"version": "v1.3.0"}`,
+		},
+		{
+			name:     "short version",
+			metadata: `{"version": "1"}`,
+			part:     Patch,
+			version:  "1.0.1",
+			want:     `{"version": "1.0.1"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modConfig, metadataPath := newVersionTestMod(t, test.metadata, test.version)
+
+			version, err := BumpVersion(modConfig, test.part)
+			if err != nil {
+				t.Fatalf("BumpVersion() error = %v", err)
+			}
+			if version != test.version {
+				t.Errorf("BumpVersion() = %q, want %q", version, test.version)
+			}
+			content, err := os.ReadFile(metadataPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.want {
+				t.Errorf("metadata = %q, want %q", content, test.want)
+			}
+		})
+	}
+}
+
+func TestBumpVersionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+	}{
+		{name: "number version", metadata: `{"version": 1}`},
+		{name: "nested version only", metadata: `{"relationships": [{"version": "1.2.3"}]}`},
+		{name: "missing version", metadata: `{"name": "Mod"}`},
+		{name: "invalid version", metadata: `{"version": "1.2.x"}`},
+		{name: "too many parts", metadata: `{"version": "1.2.3.4"}`},
+		{name: "not an object", metadata: `["version", "1.2.3"]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modConfig, metadataPath := newVersionTestMod(t, test.metadata, "1.2.4")
+
+			_, err := BumpVersion(modConfig, Patch)
+			if err == nil {
+				t.Fatal("BumpVersion() error = nil, want an error")
+			}
+			content, err := os.ReadFile(metadataPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != test.metadata {
+				t.Errorf("metadata = %q, want it unchanged", content)
+			}
+		})
+	}
+}
+
+func TestBumpVersionRequiresChangeNote(t *testing.T) {
+	modConfig, _ := newVersionTestMod(t, `{"version": "1.2.3"}`, "1.2.4")
+
+	_, err := BumpVersion(modConfig, Minor)
+	if err == nil {
+		t.Error("BumpVersion() error = nil, want an error for the missing 1.3.0 change note")
+	}
+}
+
+// newVersionTestMod creates a mod with the metadata and a change note for the given version.
+func newVersionTestMod(t *testing.T, metadata string, changeNoteVersion string) (*config.ModConfig, string) {
+	t.Helper()
+	directory := t.TempDir()
+	metadataPath := filepath.Join(directory, "mod", ".metadata", "metadata.json")
+	writeTestFile(t, metadataPath, metadata)
+	writeTestFile(t, filepath.Join(directory, "changes", changeNoteVersion+".bbcode"), "Changes")
+
+	return &config.ModConfig{
+		Directory: filepath.Join(directory, "mod"),
+		ChangeNoteDirectories: map[steam.ApiLanguage]string{
+			steam.English: filepath.Join(directory, "changes"),
+		},
+	}, metadataPath
+}
+
+func TestBumpVersionsWritesNothingIfAModFails(t *testing.T) {
+	first, firstPath := newVersionTestMod(t, `{"version": "1.2.3"}`, "1.2.4")
+	second, secondPath := newVersionTestMod(t, `{"version": "2.0.0"}`, "3.0.0")
+
+	_, err := BumpVersions([]*config.ModConfig{first, second}, Patch)
+	if err == nil {
+		t.Fatal("BumpVersions() error = nil, want an error for the missing 2.0.1 change note")
+	}
+
+	for path, want := range map[string]string{firstPath: `{"version": "1.2.3"}`, secondPath: `{"version": "2.0.0"}`} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != want {
+			t.Errorf("metadata = %q, want it unchanged", content)
+		}
+	}
+
+	versions, err := BumpVersions([]*config.ModConfig{first}, Patch)
+	if err != nil || len(versions) != 1 || versions[0] != "1.2.4" {
+		t.Errorf("BumpVersions() = %q, %v, want the first mod to be bumped once to 1.2.4", versions, err)
+	}
+}
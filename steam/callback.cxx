@@ -0,0 +1,39 @@
+#include "../sdk/public/steam/steam_api.h"
+
+// Implemented by the dispatcher in callback.go
+extern "C" void goSteamCallback(int callback, void *data);
+
+// GoCallback forwards a broadcast callback to the dispatcher while SteamAPI_RunCallbacks runs.
+class GoCallback final : public CCallbackBase {
+public:
+	GoCallback(int callback, int size) : m_size(size) {
+		m_iCallback = callback;
+	}
+
+	void Run(void *param) override {
+		goSteamCallback(m_iCallback, param);
+	}
+
+	void Run(void *param, bool, SteamAPICall_t) override {
+		goSteamCallback(m_iCallback, param);
+	}
+
+	int GetCallbackSizeBytes() override {
+		return m_size;
+	}
+
+private:
+	int m_size;
+};
+
+extern "C" void *registerGoCallback(int callback, int size) {
+	GoCallback *registered = new GoCallback(callback, size);
+	SteamAPI_RegisterCallback(registered, callback);
+	return registered;
+}
+
+extern "C" void unregisterGoCallback(void *registered) {
+	GoCallback *callback = static_cast<GoCallback *>(registered);
+	SteamAPI_UnregisterCallback(callback);
+	delete callback;
+}
@@ -0,0 +1,226 @@
+package steam
+
+/*
+void *registerGoCallback(int callback, int size);
+void unregisterGoCallback(void *registered);
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// DefaultDispatchInterval is how often SteamAPI_RunCallbacks is called if no interval is given.
+const DefaultDispatchInterval = 100 * time.Millisecond
+
+var (
+	// ErrDispatcherStopped is returned by Run if the dispatcher was stopped before the call could run.
+	ErrDispatcherStopped = errors.New("steam callback dispatcher is stopped")
+	// ErrInitFailed is returned by StartDispatcher if SteamAPI_Init failed.
+	ErrInitFailed = errors.New("failed to initialize steam api")
+)
+
+// CallbackType describes a callback struct steam broadcasts to all listeners.
+type CallbackType[T any] struct {
+	Callback int
+	Size     int
+	Wrap     func(pointer uintptr) T
+}
+
+var (
+	ItemInstalledType = CallbackType[ItemInstalled_t]{
+		ItemInstalled_tK_iCallback, Sizeof_ItemInstalled_t,
+		func(pointer uintptr) ItemInstalled_t { return SwigcptrItemInstalled_t(pointer) },
+	}
+	DownloadItemResultType = CallbackType[DownloadItemResult_t]{
+		DownloadItemResult_tK_iCallback, Sizeof_DownloadItemResult_t,
+		func(pointer uintptr) DownloadItemResult_t { return SwigcptrDownloadItemResult_t(pointer) },
+	}
+	UserSubscribedItemsListChangedType = CallbackType[UserSubscribedItemsListChanged_t]{
+		UserSubscribedItemsListChanged_tK_iCallback, Sizeof_UserSubscribedItemsListChanged_t,
+		func(pointer uintptr) UserSubscribedItemsListChanged_t {
+			return SwigcptrUserSubscribedItemsListChanged_t(pointer)
+		},
+	}
+)
+
+// Dispatcher pumps SteamAPI_RunCallbacks on a single locked OS thread
+// and routes broadcast callbacks to the registered handlers.
+// The steam API is initialized and shut down on the same thread,
+// all other steam API calls should be run on it using Do or Run.
+// Only one dispatcher can run at a time.
+type Dispatcher struct {
+	interval time.Duration
+	calls    chan func()
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	mutex       sync.Mutex
+	handlers    map[int][]*callbackHandler
+	nextHandler int
+	// registered callbacks are only accessed on the dispatcher thread
+	registered map[int]unsafe.Pointer
+}
+
+type callbackHandler struct {
+	identifier int
+	size       int
+	handle     func(pointer uintptr)
+}
+
+var runningDispatcher atomic.Pointer[Dispatcher]
+
+// StartDispatcher initializes the steam API on the dispatcher thread
+// and starts pumping callbacks every interval, 0 uses DefaultDispatchInterval.
+func StartDispatcher(interval time.Duration) (*Dispatcher, error) {
+	if interval <= 0 {
+		interval = DefaultDispatchInterval
+	}
+	dispatcher := &Dispatcher{
+		interval:   interval,
+		calls:      make(chan func()),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		handlers:   make(map[int][]*callbackHandler),
+		registered: make(map[int]unsafe.Pointer),
+	}
+	if !runningDispatcher.CompareAndSwap(nil, dispatcher) {
+		return nil, errors.New("a steam callback dispatcher is already running")
+	}
+	initialized := make(chan bool)
+	go dispatcher.run(initialized)
+	if !<-initialized {
+		return nil, ErrInitFailed
+	}
+	return dispatcher, nil
+}
+
+func (dispatcher *Dispatcher) run(initialized chan<- bool) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(dispatcher.done)
+
+	if !SteamAPI_Init() {
+		runningDispatcher.CompareAndSwap(dispatcher, nil)
+		initialized <- false
+		return
+	}
+	initialized <- true
+
+	ticker := time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case call := <-dispatcher.calls:
+			call()
+		case <-ticker.C:
+			dispatcher.registerCallbacks()
+			SteamAPI_RunCallbacks()
+		case <-dispatcher.stop:
+			for _, registered := range dispatcher.registered {
+				C.unregisterGoCallback(registered)
+			}
+			SteamAPI_Shutdown()
+			runningDispatcher.CompareAndSwap(dispatcher, nil)
+			return
+		}
+	}
+}
+
+// registerCallbacks registers callbacks with steam that got their first handler since the last run.
+func (dispatcher *Dispatcher) registerCallbacks() {
+	dispatcher.mutex.Lock()
+	defer dispatcher.mutex.Unlock()
+	for callback, handlers := range dispatcher.handlers {
+		if _, ok := dispatcher.registered[callback]; ok || len(handlers) == 0 {
+			continue
+		}
+		dispatcher.registered[callback] = C.registerGoCallback(C.int(callback), C.int(handlers[0].size))
+	}
+}
+
+// Do runs the call on the dispatcher thread and waits until it returned.
+// It returns false without running the call if the dispatcher is stopped.
+// Do must not be called from a callback handler, as handlers already run on the dispatcher thread.
+func (dispatcher *Dispatcher) Do(call func()) bool {
+	finished := make(chan struct{})
+	select {
+	case dispatcher.calls <- func() {
+		defer close(finished)
+		call()
+	}:
+	case <-dispatcher.done:
+		return false
+	}
+	<-finished
+	return true
+}
+
+// Run is Do returning ErrDispatcherStopped if the call did not run.
+func (dispatcher *Dispatcher) Run(call func()) error {
+	if !dispatcher.Do(call) {
+		return ErrDispatcherStopped
+	}
+	return nil
+}
+
+// Stop unregisters all callbacks, shuts the steam API down and waits until the dispatcher thread finished.
+func (dispatcher *Dispatcher) Stop() {
+	dispatcher.stopOnce.Do(func() {
+		close(dispatcher.stop)
+	})
+	<-dispatcher.done
+}
+
+// RegisterCallback calls the handler on the dispatcher thread with every callback of the type
+// until the returned function is called.
+// The callback struct is only valid while the handler runs.
+func RegisterCallback[T any](dispatcher *Dispatcher, callbackType CallbackType[T], handler func(T)) (unregister func()) {
+	dispatcher.mutex.Lock()
+	defer dispatcher.mutex.Unlock()
+
+	dispatcher.nextHandler++
+	registered := &callbackHandler{
+		identifier: dispatcher.nextHandler,
+		size:       callbackType.Size,
+		handle: func(pointer uintptr) {
+			handler(callbackType.Wrap(pointer))
+		},
+	}
+	dispatcher.handlers[callbackType.Callback] = append(dispatcher.handlers[callbackType.Callback], registered)
+
+	return func() {
+		dispatcher.mutex.Lock()
+		defer dispatcher.mutex.Unlock()
+		dispatcher.handlers[callbackType.Callback] = slices.DeleteFunc(
+			dispatcher.handlers[callbackType.Callback],
+			func(handler *callbackHandler) bool {
+				return handler.identifier == registered.identifier
+			},
+		)
+	}
+}
+
+//export goSteamCallback
+func goSteamCallback(callback C.int, data unsafe.Pointer) {
+	dispatcher := runningDispatcher.Load()
+	if dispatcher == nil {
+		return
+	}
+
+	// Handlers may register or unregister handlers themselves
+	dispatcher.mutex.Lock()
+	handlers := slices.Clone(dispatcher.handlers[int(callback)])
+	dispatcher.mutex.Unlock()
+
+	for _, handler := range handlers {
+		handler.handle(uintptr(data))
+	}
+}
@@ -0,0 +1,117 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// pollInterval is the wait between two checks if an API call completed
+const pollInterval = 500 * time.Millisecond
+
+// CallResult is a result struct of an asynchronous steam API call.
+type CallResult interface {
+	Swigcptr() uintptr
+	GetM_eResult() EResult
+}
+
+// CallResultType describes how the result struct of an API call is allocated and read.
+type CallResultType[T CallResult] struct {
+	Size     int
+	Callback int
+	New      func() T
+	Delete   func(T)
+	// NewError creates the error of a result other than K_EResultOK
+	NewError func(EResult) *ResultError
+}
+
+var (
+	CreateItemResultType = CallResultType[CreateItemResult_t]{
+		Sizeof_CreateItemResult_t, CreateItemResult_tK_iCallback, NewCreateItemResult_t, DeleteCreateItemResult_t, NewResultError,
+	}
+	SubmitItemUpdateResultType = CallResultType[SubmitItemUpdateResult_t]{
+		Sizeof_SubmitItemUpdateResult_t, SubmitItemUpdateResult_tK_iCallback, NewSubmitItemUpdateResult_t, DeleteSubmitItemUpdateResult_t, NewItemUpdateError,
+	}
+	SteamUGCQueryCompletedType = CallResultType[SteamUGCQueryCompleted_t]{
+		Sizeof_SteamUGCQueryCompleted_t, SteamUGCQueryCompleted_tK_iCallback, NewSteamUGCQueryCompleted_t, DeleteSteamUGCQueryCompleted_t, NewResultError,
+	}
+	AddUGCDependencyResultType = CallResultType[AddUGCDependencyResult_t]{
+		Sizeof_AddUGCDependencyResult_t, AddUGCDependencyResult_tK_iCallback, NewAddUGCDependencyResult_t, DeleteAddUGCDependencyResult_t, NewResultError,
+	}
+	RemoveUGCDependencyResultType = CallResultType[RemoveUGCDependencyResult_t]{
+		Sizeof_RemoveUGCDependencyResult_t, RemoveUGCDependencyResult_tK_iCallback, NewRemoveUGCDependencyResult_t, DeleteRemoveUGCDependencyResult_t, NewResultError,
+	}
+	GetAppDependenciesResultType = CallResultType[GetAppDependenciesResult_t]{
+		Sizeof_GetAppDependenciesResult_t, GetAppDependenciesResult_tK_iCallback, NewGetAppDependenciesResult_t, DeleteGetAppDependenciesResult_t, NewResultError,
+	}
+	AddAppDependencyResultType = CallResultType[AddAppDependencyResult_t]{
+		Sizeof_AddAppDependencyResult_t, AddAppDependencyResult_tK_iCallback, NewAddAppDependencyResult_t, DeleteAddAppDependencyResult_t, NewResultError,
+	}
+	RemoveAppDependencyResultType = CallResultType[RemoveAppDependencyResult_t]{
+		Sizeof_RemoveAppDependencyResult_t, RemoveAppDependencyResult_tK_iCallback, NewRemoveAppDependencyResult_t, DeleteRemoveAppDependencyResult_t, NewResultError,
+	}
+	DeleteItemResultType = CallResultType[DeleteItemResult_t]{
+		Sizeof_DeleteItemResult_t, DeleteItemResult_tK_iCallback, NewDeleteItemResult_t, DeleteDeleteItemResult_t, NewResultError,
+	}
+)
+
+// AwaitCallResult waits until the API call completed and returns its result,
+// which has to be freed using the Delete function of the result type.
+// The poll function is called while the call is running and may be nil.
+// The call is checked and the poll function is run on the dispatcher thread.
+//
+// A *ResultError is returned if the call failed or completed with a result other than K_EResultOK.
+// Waiting stops with the cause of the context once it is done
+// and with ErrDispatcherStopped if the dispatcher is stopped.
+func AwaitCallResult[T CallResult](ctx context.Context, dispatcher *Dispatcher, apiCall uint64, resultType CallResultType[T], poll func()) (T, error) {
+	var empty T
+	var completed, failed bool
+	for {
+		err := dispatcher.Run(func() {
+			completed = SteamUtils().IsAPICallCompleted(apiCall, &failed)
+			if !completed && poll != nil {
+				poll()
+			}
+		})
+		if err != nil {
+			return empty, err
+		}
+		if completed {
+			break
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return empty, fmt.Errorf("stopped waiting for steam: %w", context.Cause(ctx))
+		case <-timer.C:
+		}
+	}
+
+	result := resultType.New()
+	var failure ESteamAPICallFailure
+	err := dispatcher.Run(func() {
+		if !failed && !SteamUtils().GetAPICallResult(apiCall, result.Swigcptr(), resultType.Size, resultType.Callback, &failed) {
+			failed = true
+		}
+		if failed {
+			failure = SteamUtils().GetAPICallFailureReason(apiCall)
+		}
+	})
+	if err != nil {
+		resultType.Delete(result)
+		return empty, err
+	}
+	if failed {
+		resultType.Delete(result)
+		return empty, NewCallFailureError(failure)
+	}
+
+	if result.GetM_eResult() != K_EResultOK {
+		err := resultType.NewError(result.GetM_eResult())
+		resultType.Delete(result)
+		return empty, err
+	}
+	return result, nil
+}
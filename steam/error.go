@@ -0,0 +1,99 @@
+package steam
+
+import (
+	"fmt"
+	"slices"
+)
+
+// retryableResults are failures caused by steam being temporarily unavailable,
+// the same call may succeed if it is repeated later.
+var retryableResults = []EResult{
+	K_EResultNoConnection,
+	K_EResultBusy,
+	K_EResultTimeout,
+	K_EResultServiceUnavailable,
+	K_EResultConnectFailed,
+	K_EResultRemoteDisconnect,
+	K_EResultTryAnotherCM,
+	K_EResultRateLimitExceeded,
+}
+
+// Errors of common results to check for with errors.Is, e.g. errors.Is(err, steam.ErrNoConnection).
+var (
+	ErrNoConnection          = NewResultError(K_EResultNoConnection)
+	ErrAccessDenied          = NewResultError(K_EResultAccessDenied)
+	ErrFileNotFound          = NewResultError(K_EResultFileNotFound)
+	ErrLimitExceeded         = NewResultError(K_EResultLimitExceeded)
+	ErrInsufficientPrivilege = NewResultError(K_EResultInsufficientPrivilege)
+	ErrNotLoggedOn           = NewResultError(K_EResultNotLoggedOn)
+	ErrBusy                  = NewResultError(K_EResultBusy)
+	ErrTimeout               = NewResultError(K_EResultTimeout)
+	ErrServiceUnavailable    = NewResultError(K_EResultServiceUnavailable)
+	ErrRateLimitExceeded     = NewResultError(K_EResultRateLimitExceeded)
+	ErrSteamGone             = NewCallFailureError(K_ESteamAPICallFailureSteamGone)
+	ErrNetworkFailure        = NewCallFailureError(K_ESteamAPICallFailureNetworkFailure)
+)
+
+// ResultError is returned if a steam API call failed or finished with a result other than K_EResultOK.
+// Failure is K_ESteamAPICallFailureNone if the call completed, Result is K_EResultNone if it did not.
+type ResultError struct {
+	Result      EResult
+	Failure     ESteamAPICallFailure
+	Description string
+	// Hint tells the user how the failure can be fixed, it is empty if there is nothing to do
+	Hint string
+}
+
+// NewResultError creates an error for the result of a completed call using the generic result description.
+func NewResultError(result EResult) *ResultError {
+	return &ResultError{
+		Result:      result,
+		Failure:     K_ESteamAPICallFailureNone,
+		Description: ResultDescription[result],
+		Hint:        ResultHint[result],
+	}
+}
+
+// NewItemUpdateError creates an error for the result of a submitted item update,
+// preferring the description specific to item updates.
+func NewItemUpdateError(result EResult) *ResultError {
+	err := NewResultError(result)
+	if description := UgcItemUpdateDescription[result]; description != "" {
+		err.Description = description
+	}
+	return err
+}
+
+// NewCallFailureError creates an error for a call that failed without a result.
+func NewCallFailureError(failure ESteamAPICallFailure) *ResultError {
+	return &ResultError{
+		Result:      K_EResultNone,
+		Failure:     failure,
+		Description: CallFailureDescription[failure],
+		Hint:        CallFailureHint[failure],
+	}
+}
+
+func (err *ResultError) Error() string {
+	if err.Hint == "" {
+		return "steam API call failed: " + err.Description
+	}
+	return fmt.Sprintf("steam API call failed: %s (%s)", err.Description, err.Hint)
+}
+
+// Is reports if the target is a ResultError of the same result and failure,
+// descriptions and hints are not compared.
+func (err *ResultError) Is(target error) bool {
+	other, ok := target.(*ResultError)
+	return ok && err.Result == other.Result && err.Failure == other.Failure
+}
+
+// Retryable reports if the call failed because steam was temporarily unavailable.
+func (err *ResultError) Retryable() bool {
+	return IsRetryableResult(err.Result) || err.Failure == K_ESteamAPICallFailureNetworkFailure
+}
+
+// IsRetryableResult reports if a call that failed with the result may succeed if it is repeated.
+func IsRetryableResult(result EResult) bool {
+	return slices.Contains(retryableResults, result)
+}
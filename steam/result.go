@@ -120,3 +120,34 @@ var UgcItemUpdateDescription = map[EResult]string{
 	K_EResultFileNotFound:  "The provided content folder is not valid.",
 	K_EResultLimitExceeded: "The preview image is too large, it must be less than 1 Megabyte; or there is not enough space available on the user's Steam Cloud.",
 }
+
+var CallFailureDescription = map[ESteamAPICallFailure]string{
+	K_ESteamAPICallFailureNone:               "No failure.",
+	K_ESteamAPICallFailureSteamGone:          "The local Steam process has stopped responding, it may have been shutdown or crashed.",
+	K_ESteamAPICallFailureNetworkFailure:     "The network connection to the Steam servers has been lost, or was already broken.",
+	K_ESteamAPICallFailureInvalidHandle:      "The API call handle passed in no longer exists.",
+	K_ESteamAPICallFailureMismatchedCallback: "GetAPICallResult was called with the wrong callback type for this API call.",
+}
+
+// ResultHint tells the user how to fix a failed workshop call.
+var ResultHint = map[EResult]string{
+	K_EResultNoConnection:          "the steam client is offline, check that steam is running and connected",
+	K_EResultNotLoggedOn:           "log in to the steam client and try again",
+	K_EResultAccessDenied:          "check that the logged in steam account owns the game and the workshop item",
+	K_EResultFileNotFound:          "check that the workshop item exists and the mod directory is valid",
+	K_EResultInvalidParam:          "check that the game in the config matches the workshop item",
+	K_EResultLimitExceeded:         "a file is too large, check that the thumbnail is smaller than 1 MB or enable convert-thumbnail, and that there is enough space in the steam cloud",
+	K_EResultInsufficientPrivilege: "the steam account is not allowed to upload to the workshop, check for community or trade restrictions",
+	K_EResultBanned:                "the steam account is banned from uploading to the workshop",
+	K_EResultBusy:                  "steam is busy, try again later",
+	K_EResultTimeout:               "steam did not answer in time, try again later",
+	K_EResultServiceUnavailable:    "the workshop is temporarily unavailable, try again later",
+	K_EResultRateLimitExceeded:     "too many uploads in a short time, wait a few minutes before trying again",
+	K_EResultLockingFailed:         "another upload of the item is running, wait until it finished",
+}
+
+// CallFailureHint tells the user how to fix an API call that failed without a result.
+var CallFailureHint = map[ESteamAPICallFailure]string{
+	K_ESteamAPICallFailureSteamGone:      "the steam client was closed or crashed, restart steam",
+	K_ESteamAPICallFailureNetworkFailure: "check your internet connection",
+}
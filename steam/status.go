@@ -0,0 +1,10 @@
+package steam
+
+var ItemUpdateStatusDescription = map[EItemUpdateStatus]string{
+	K_EItemUpdateStatusInvalid:              "Invalid update handle or finished.",
+	K_EItemUpdateStatusPreparingConfig:      "Processing configuration data.",
+	K_EItemUpdateStatusPreparingContent:     "Reading and processing content files.",
+	K_EItemUpdateStatusUploadingContent:     "Uploading content changes to Steam.",
+	K_EItemUpdateStatusUploadingPreviewFile: "Uploading new preview file image.",
+	K_EItemUpdateStatusCommittingChanges:    "Committing all changes.",
+}
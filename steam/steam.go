@@ -6426,11 +6426,27 @@ extern _Bool _wrap_SteamAPI_ManualDispatch_GetAPICallResult_steam_fb253aa6b56548
 extern swig_intgo _wrap_SteamInternal_SteamAPI_Init_steam_fb253aa6b5654893(swig_type_1061 arg1, swig_voidp arg2);
 extern swig_intgo _wrap_sizeof_CreateItemResult_t_steam_fb253aa6b5654893(void);
 extern swig_intgo _wrap_sizeof_SubmitItemUpdateResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_SteamUGCQueryCompleted_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_AddUGCDependencyResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_RemoveUGCDependencyResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_AddAppDependencyResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_RemoveAppDependencyResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_GetAppDependenciesResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_ItemInstalled_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_DownloadItemResult_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_UserSubscribedItemsListChanged_t_steam_fb253aa6b5654893(void);
+extern swig_intgo _wrap_sizeof_DeleteItemResult_t_steam_fb253aa6b5654893(void);
 #undef intgo
 typedef struct {
     const char **m_ppStrings;
     int m_nNumStrings;
 } SteamParamStringArray_t;
+
+#include <stdlib.h>
+
+extern _Bool SteamAPI_ISteamUGC_GetQueryUGCAdditionalPreview(uintptr_t self, uint64_t handle, uint32_t index, uint32_t previewIndex, char *pchURLOrVideoID, uint32_t cchURLSize, char *pchOriginalFileName, uint32_t cchOriginalFileNameSize, int *pPreviewType);
+extern _Bool SteamAPI_ISteamUGC_GetQueryUGCMetadata(uintptr_t self, uint64_t handle, uint32_t index, char *pchMetadata, uint32_t cchMetadatasize);
+extern _Bool SteamAPI_ISteamUGC_GetQueryUGCKeyValueTag(uintptr_t self, uint64_t handle, uint32_t index, uint32_t keyValueTagIndex, char *pchKey, uint32_t cchKeySize, char *pchValue, uint32_t cchValueSize);
 */
 import "C"
 
@@ -40897,6 +40913,9 @@ type ISteamUGC interface {
 	SetItemsDisabledLocally(arg2 *uint64, arg3 uint, arg4 bool) (_swig_ret bool)
 	SetSubscriptionsLoadOrder(arg2 *uint64, arg3 uint) (_swig_ret bool)
 	SetItemTagsExtension(arg2 uint64, arg3 *C.SteamParamStringArray_t) (_swig_ret bool)
+	GetQueryUGCAdditionalPreviewExtension(arg2 uint64, arg3 uint, arg4 uint) (urlOrVideoID string, originalFileName string, previewType EItemPreviewType, ok bool)
+	GetQueryUGCMetadataExtension(arg2 uint64, arg3 uint) (metadata string, ok bool)
+	GetQueryUGCKeyValueTagExtension(arg2 uint64, arg3 uint, arg4 uint) (key string, value string, ok bool)
 }
 
 const STEAMUGC_INTERFACE_VERSION string = "STEAMUGC_INTERFACE_VERSION021"
@@ -42220,6 +42239,7 @@ type GetAppDependenciesResult_t interface {
 	GetM_nNumAppDependencies() (_swig_ret uint)
 	SetM_nTotalNumAppDependencies(arg2 uint)
 	GetM_nTotalNumAppDependencies() (_swig_ret uint)
+	GetAppIDsExtension() []uint
 }
 
 type SwigcptrDeleteItemResult_t uintptr
@@ -54164,6 +54184,86 @@ func _swig_getsizeof_SubmitItemUpdateResult_t() (_swig_ret int) {
 
 var Sizeof_SubmitItemUpdateResult_t int = _swig_getsizeof_SubmitItemUpdateResult_t()
 
+func _swig_getsizeof_SteamUGCQueryCompleted_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_SteamUGCQueryCompleted_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_SteamUGCQueryCompleted_t int = _swig_getsizeof_SteamUGCQueryCompleted_t()
+
+func _swig_getsizeof_AddUGCDependencyResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_AddUGCDependencyResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_AddUGCDependencyResult_t int = _swig_getsizeof_AddUGCDependencyResult_t()
+
+func _swig_getsizeof_RemoveUGCDependencyResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_RemoveUGCDependencyResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_RemoveUGCDependencyResult_t int = _swig_getsizeof_RemoveUGCDependencyResult_t()
+
+func _swig_getsizeof_AddAppDependencyResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_AddAppDependencyResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_AddAppDependencyResult_t int = _swig_getsizeof_AddAppDependencyResult_t()
+
+func _swig_getsizeof_RemoveAppDependencyResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_RemoveAppDependencyResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_RemoveAppDependencyResult_t int = _swig_getsizeof_RemoveAppDependencyResult_t()
+
+func _swig_getsizeof_GetAppDependenciesResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_GetAppDependenciesResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_GetAppDependenciesResult_t int = _swig_getsizeof_GetAppDependenciesResult_t()
+
+func _swig_getsizeof_ItemInstalled_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_ItemInstalled_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_ItemInstalled_t int = _swig_getsizeof_ItemInstalled_t()
+
+func _swig_getsizeof_DownloadItemResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_DownloadItemResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_DownloadItemResult_t int = _swig_getsizeof_DownloadItemResult_t()
+
+func _swig_getsizeof_UserSubscribedItemsListChanged_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_UserSubscribedItemsListChanged_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_UserSubscribedItemsListChanged_t int = _swig_getsizeof_UserSubscribedItemsListChanged_t()
+
+func _swig_getsizeof_DeleteItemResult_t() (_swig_ret int) {
+	var swig_r int
+	swig_r = (int)(C._wrap_sizeof_DeleteItemResult_t_steam_fb253aa6b5654893())
+	return swig_r
+}
+
+var Sizeof_DeleteItemResult_t int = _swig_getsizeof_DeleteItemResult_t()
+
 type SwigcptrISteamGameServerStats uintptr
 type ISteamGameServerStats interface {
 	Swigcptr() uintptr
@@ -54316,3 +54416,94 @@ func (arg1 SwigcptrISteamUGC) SetItemTagsExtension(arg2 uint64, arg3 *C.SteamPar
 	))
 	return swig_r
 }
+
+const ugcStringBufferSize = 1024
+
+// Developer metadata is limited to 5000 bytes by steam
+const ugcMetadataBufferSize = 5000 + 1
+
+// GetQueryUGCAdditionalPreviewExtension calls the flat steam API directly,
+// because the generated wrapper copies the output buffers and loses the result.
+func (arg1 SwigcptrISteamUGC) GetQueryUGCAdditionalPreviewExtension(arg2 uint64, arg3 uint, arg4 uint) (urlOrVideoID string, originalFileName string, previewType EItemPreviewType, ok bool) {
+	urlBuffer := (*C.char)(C.malloc(C.size_t(ugcStringBufferSize)))
+	defer C.free(unsafe.Pointer(urlBuffer))
+	fileNameBuffer := (*C.char)(C.malloc(C.size_t(ugcStringBufferSize)))
+	defer C.free(unsafe.Pointer(fileNameBuffer))
+
+	var cPreviewType C.int
+	ok = bool(C.SteamAPI_ISteamUGC_GetQueryUGCAdditionalPreview(
+		C.uintptr_t(arg1),
+		C.uint64_t(arg2),
+		C.uint32_t(arg3),
+		C.uint32_t(arg4),
+		urlBuffer,
+		C.uint32_t(ugcStringBufferSize),
+		fileNameBuffer,
+		C.uint32_t(ugcStringBufferSize),
+		&cPreviewType,
+	))
+	if !ok {
+		return "", "", K_EItemPreviewType_Image, false
+	}
+	return C.GoString(urlBuffer), C.GoString(fileNameBuffer), EItemPreviewType(cPreviewType), true
+}
+
+// GetAppIDsExtension reads the returned app ids,
+// the generated getter only exposes a pointer to the uint32 array.
+func (arg1 SwigcptrGetAppDependenciesResult_t) GetAppIDsExtension() []uint {
+	// The result holds at most 32 app ids per callback
+	count := min(arg1.GetM_nNumAppDependencies(), 32)
+	if count == 0 {
+		return nil
+	}
+
+	appIDs := unsafe.Slice((*uint32)(unsafe.Pointer(arg1.GetM_rgAppIDs())), count)
+	result := make([]uint, count)
+	for i, appID := range appIDs {
+		result[i] = uint(appID)
+	}
+	return result
+}
+
+// GetQueryUGCMetadataExtension calls the flat steam API directly,
+// because the generated wrapper can not return the metadata buffer.
+func (arg1 SwigcptrISteamUGC) GetQueryUGCMetadataExtension(arg2 uint64, arg3 uint) (metadata string, ok bool) {
+	metadataBuffer := (*C.char)(C.malloc(C.size_t(ugcMetadataBufferSize)))
+	defer C.free(unsafe.Pointer(metadataBuffer))
+
+	ok = bool(C.SteamAPI_ISteamUGC_GetQueryUGCMetadata(
+		C.uintptr_t(arg1),
+		C.uint64_t(arg2),
+		C.uint32_t(arg3),
+		metadataBuffer,
+		C.uint32_t(ugcMetadataBufferSize),
+	))
+	if !ok {
+		return "", false
+	}
+	return C.GoString(metadataBuffer), true
+}
+
+// GetQueryUGCKeyValueTagExtension calls the flat steam API directly,
+// because the generated wrapper can not return the key and value buffers.
+func (arg1 SwigcptrISteamUGC) GetQueryUGCKeyValueTagExtension(arg2 uint64, arg3 uint, arg4 uint) (key string, value string, ok bool) {
+	keyBuffer := (*C.char)(C.malloc(C.size_t(ugcStringBufferSize)))
+	defer C.free(unsafe.Pointer(keyBuffer))
+	valueBuffer := (*C.char)(C.malloc(C.size_t(ugcStringBufferSize)))
+	defer C.free(unsafe.Pointer(valueBuffer))
+
+	ok = bool(C.SteamAPI_ISteamUGC_GetQueryUGCKeyValueTag(
+		C.uintptr_t(arg1),
+		C.uint64_t(arg2),
+		C.uint32_t(arg3),
+		C.uint32_t(arg4),
+		keyBuffer,
+		C.uint32_t(ugcStringBufferSize),
+		valueBuffer,
+		C.uint32_t(ugcStringBufferSize),
+	))
+	if !ok {
+		return "", "", false
+	}
+	return C.GoString(keyBuffer), C.GoString(valueBuffer), true
+}
@@ -22,3 +22,13 @@ INCL("../sdk/public/steam/steam_api.h")
 // need to get the size of this types
 %sizeof(CreateItemResult_t)
 %sizeof(SubmitItemUpdateResult_t)
+%sizeof(SteamUGCQueryCompleted_t)
+%sizeof(AddUGCDependencyResult_t)
+%sizeof(RemoveUGCDependencyResult_t)
+%sizeof(AddAppDependencyResult_t)
+%sizeof(RemoveAppDependencyResult_t)
+%sizeof(GetAppDependenciesResult_t)
+%sizeof(ItemInstalled_t)
+%sizeof(DownloadItemResult_t)
+%sizeof(UserSubscribedItemsListChanged_t)
+%sizeof(DeleteItemResult_t)
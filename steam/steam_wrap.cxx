@@ -71461,6 +71461,116 @@ intgo _wrap_sizeof_SubmitItemUpdateResult_t_steam_fb253aa6b5654893() {
 }
 
 
+intgo _wrap_sizeof_SteamUGCQueryCompleted_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(SteamUGCQueryCompleted_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_AddUGCDependencyResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(AddUGCDependencyResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_RemoveUGCDependencyResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(RemoveUGCDependencyResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_AddAppDependencyResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(AddAppDependencyResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_RemoveAppDependencyResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(RemoveAppDependencyResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_GetAppDependenciesResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(GetAppDependenciesResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_ItemInstalled_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(ItemInstalled_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_DownloadItemResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(DownloadItemResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_UserSubscribedItemsListChanged_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(UserSubscribedItemsListChanged_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
+intgo _wrap_sizeof_DeleteItemResult_t_steam_fb253aa6b5654893() {
+  int result;
+  intgo _swig_go_result;
+  
+  
+  result = (int)(sizeof(DeleteItemResult_t));
+  _swig_go_result = result; 
+  return _swig_go_result;
+}
+
+
 #ifdef __cplusplus
 }
 #endif
@@ -0,0 +1,52 @@
+package steam
+
+type Visibility string
+
+const (
+	Public      Visibility = "public"
+	FriendsOnly Visibility = "friends-only"
+	Unlisted    Visibility = "unlisted"
+	Private     Visibility = "private"
+)
+
+func (visibility Visibility) GetString() string {
+	return string(visibility)
+}
+
+func (visibility Visibility) IsValid() bool {
+	_, ok := Visibilities[visibility]
+	return ok
+}
+
+func (visibility Visibility) GetValue() ERemoteStoragePublishedFileVisibility {
+	switch visibility {
+	case Public:
+		return K_ERemoteStoragePublishedFileVisibilityPublic
+	case FriendsOnly:
+		return K_ERemoteStoragePublishedFileVisibilityFriendsOnly
+	case Unlisted:
+		return K_ERemoteStoragePublishedFileVisibilityUnlisted
+	default:
+		return K_ERemoteStoragePublishedFileVisibilityPrivate
+	}
+}
+
+func VisibilityFromValue(value ERemoteStoragePublishedFileVisibility) Visibility {
+	switch value {
+	case K_ERemoteStoragePublishedFileVisibilityPublic:
+		return Public
+	case K_ERemoteStoragePublishedFileVisibilityFriendsOnly:
+		return FriendsOnly
+	case K_ERemoteStoragePublishedFileVisibilityUnlisted:
+		return Unlisted
+	default:
+		return Private
+	}
+}
+
+var Visibilities = map[Visibility]string{
+	Public:      "Public",
+	FriendsOnly: "Friends Only",
+	Unlisted:    "Unlisted",
+	Private:     "Private",
+}
@@ -5,12 +5,14 @@ package web
 import (
 	"embed"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
 	"net"
 	"net/http"
 	"strconv"
+	"sync"
 
 	"bahmut.de/pdx-workshop-manager/config"
 	"bahmut.de/pdx-workshop-manager/logging"
@@ -43,6 +45,14 @@ type Message struct {
 	Message string
 }
 
+type Progress struct {
+	Identifier uint64 `json:"identifier"`
+	Language   string `json:"language"`
+	Status     string `json:"status"`
+	Percentage int    `json:"percentage"`
+	Finished   bool   `json:"finished"`
+}
+
 type MainWindow struct {
 	Message       *Message
 	Games         []*Game
@@ -50,6 +60,9 @@ type MainWindow struct {
 	Configuration *config.ApplicationConfig
 	Mods          []*ModFrame
 	Languages     map[steam.ApiLanguage]string
+	Visibilities  map[steam.Visibility]string
+	progress      *Progress
+	progressLock  sync.Mutex
 }
 
 func (w *MainWindow) RefreshMods() {
@@ -68,6 +81,24 @@ func (w *MainWindow) RefreshGame() {
 	}
 }
 
+func (w *MainWindow) UpdateProgress(progress manager.UploadProgress) {
+	w.progressLock.Lock()
+	defer w.progressLock.Unlock()
+	w.progress = &Progress{
+		Identifier: progress.Identifier,
+		Language:   steam.ApiLanguages[progress.Language],
+		Status:     progress.StatusDescription(),
+		Percentage: progress.Percentage(),
+		Finished:   progress.Finished,
+	}
+}
+
+func (w *MainWindow) GetProgress() *Progress {
+	w.progressLock.Lock()
+	defer w.progressLock.Unlock()
+	return w.progress
+}
+
 func (w *MainWindow) SendMessage(message string, level int) {
 	w.Message = &Message{
 		Shown:   false,
@@ -85,9 +116,11 @@ func Run() {
 		Games:         games,
 		Configuration: configuration,
 		Languages:     steam.ApiLanguages,
+		Visibilities:  steam.Visibilities,
 	}
 	window.RefreshMods()
 	window.RefreshGame()
+	manager.SubscribeProgress(window.UpdateProgress)
 
 	http.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(static.Embed))))
 	http.HandleFunc("GET /", main)
@@ -97,6 +130,8 @@ func Run() {
 	http.HandleFunc("POST /mod/update/{index}", updateMod)
 	http.HandleFunc("GET /mod/remove/{index}", removeMod)
 	http.HandleFunc("GET /mod/upload/{index}", uploadMod)
+	http.HandleFunc("POST /mod/delete/{index}", deleteMod)
+	http.HandleFunc("GET /mod/progress", uploadProgress)
 	http.HandleFunc("GET /mod/{index}/name/add/{language}", addModName)
 	http.HandleFunc("GET /mod/{index}/name/remove/{language}", removeModName)
 	http.HandleFunc("GET /mod/{index}/description/add/{language}", addModDescription)
@@ -472,16 +507,16 @@ func uploadMod(writer http.ResponseWriter, request *http.Request) {
 	}
 
 	err = manager.Init(window.Configuration)
-	defer steam.SteamAPI_Shutdown()
 	if err != nil {
 		window.SendMessage(fmt.Sprintf("Failed to initialize steam: %v", err), MessageError)
 		http.Redirect(writer, request, "/", http.StatusSeeOther)
 		return
 	}
+	defer manager.Shutdown()
 
-	err = manager.UploadMod(window.Configuration, window.Configuration.Mods[index])
+	err = manager.UploadMod(request.Context(), window.Configuration, window.Configuration.Mods[index], manager.UploadOptions{Mode: manager.UploadFull})
 	if err != nil {
-		window.SendMessage(fmt.Sprintf("Could not upload mod: %v", err), MessageError)
+		window.SendMessage(failureMessage("upload", err))
 	} else {
 		window.SendMessage(fmt.Sprintf("Uploaded mod successfully: %d", window.Configuration.Mods[index].Identifier), MessageSuccess)
 	}
@@ -489,6 +524,79 @@ func uploadMod(writer http.ResponseWriter, request *http.Request) {
 	http.Redirect(writer, request, "/", http.StatusSeeOther)
 }
 
+func deleteMod(writer http.ResponseWriter, request *http.Request) {
+	indexParameter := request.PathValue("index")
+	index, err := strconv.Atoi(indexParameter)
+	if err != nil {
+		window.SendMessage(fmt.Sprintf("Could not parse mod index: %v", err), MessageError)
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+		return
+	}
+
+	if index < 0 || index >= len(window.Configuration.Mods) {
+		window.SendMessage(fmt.Sprintf("Could not delete mod: %v", errors.New("index out of bound")), MessageError)
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+		return
+	}
+
+	if err := request.ParseForm(); err != nil {
+		window.SendMessage(fmt.Sprintf("Could not delete mod: %v", err), MessageError)
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+		return
+	}
+
+	// Deleting can not be undone, so the workshop id has to be typed to confirm it
+	identifier := window.Configuration.Mods[index].Identifier
+	if request.FormValue("confirmation") != strconv.FormatUint(identifier, 10) {
+		window.SendMessage(fmt.Sprintf("Type the workshop id %d to confirm deleting the workshop item", identifier), MessageWarning)
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+		return
+	}
+
+	err = manager.Init(window.Configuration)
+	if err != nil {
+		window.SendMessage(fmt.Sprintf("Failed to initialize steam: %v", err), MessageError)
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+		return
+	}
+	defer manager.Shutdown()
+
+	err = manager.DeleteMod(request.Context(), window.Configuration, window.Configuration.Mods[index])
+	if err != nil {
+		window.SendMessage(failureMessage("delete", err))
+	} else {
+		window.SendMessage(fmt.Sprintf("Deleted workshop item successfully: %d", identifier), MessageSuccess)
+	}
+	window.RefreshMods()
+	http.Redirect(writer, request, "/", http.StatusSeeOther)
+}
+
+// failureMessage explains a failed upload or delete depending on why it failed.
+func failureMessage(action string, err error) (string, int) {
+	var invalidMod *manager.InvalidModError
+	var resultError *steam.ResultError
+	switch {
+	case errors.As(err, &invalidMod):
+		return fmt.Sprintf("Could not %s mod, the mod is invalid and steam was not contacted: %v", action, invalidMod.Err), MessageError
+	case errors.Is(err, manager.ErrCallTimeout):
+		return fmt.Sprintf("Steam did not respond in time, try again later: %v", err), MessageWarning
+	case errors.As(err, &resultError) && resultError.Retryable():
+		return fmt.Sprintf("Steam is temporarily unavailable, try again later: %s", resultError.Description), MessageWarning
+	case errors.As(err, &resultError) && resultError.Hint != "":
+		return fmt.Sprintf("Steam rejected the %s: %s Hint: %s", action, resultError.Description, resultError.Hint), MessageError
+	default:
+		return fmt.Sprintf("Could not %s mod: %v", action, err), MessageError
+	}
+}
+
+func uploadProgress(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(window.GetProgress())
+	if err != nil {
+		logging.Errorf("Could not encode upload progress: %v", err)
+	}
+}
+
 func updateMod(writer http.ResponseWriter, request *http.Request) {
 	indexParameter := request.PathValue("index")
 	index, err := strconv.Atoi(indexParameter)
@@ -513,6 +621,7 @@ func updateMod(writer http.ResponseWriter, request *http.Request) {
 	identifierValue := request.FormValue("identifier")
 	thumbnail := request.FormValue("thumbnail")
 	directory := request.FormValue("directory")
+	visibility := steam.Visibility(request.FormValue("visibility"))
 
 	for language := range steam.ApiLanguages {
 		description := request.FormValue(fmt.Sprintf("description-%v", language))
@@ -536,9 +645,16 @@ func updateMod(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	if visibility != "" && !visibility.IsValid() {
+		window.SendMessage(fmt.Sprintf("Invalid mod visibility: %s", visibility), MessageError)
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+		return
+	}
+
 	window.Configuration.Mods[index].Identifier = identifier
 	window.Configuration.Mods[index].Thumbnail = thumbnail
 	window.Configuration.Mods[index].Directory = directory
+	window.Configuration.Mods[index].Visibility = visibility
 
 	err = window.Configuration.Save()
 	if err != nil {